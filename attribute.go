@@ -0,0 +1,291 @@
+package ldif
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttributeDescription is an attribute type with its options, as in
+// "cn;lang-en" or "userCertificate;binary". Options are compared
+// case-insensitively and their order is not significant.
+type AttributeDescription struct {
+	// Type is either a name or a dotted-decimal OID.
+	Type    string
+	Options []string
+}
+
+// ParseAttributeDescription parses an AttributeDescription.
+func ParseAttributeDescription(s string) (AttributeDescription, error) {
+	if !matches(attributeDescription, s) {
+		return AttributeDescription{}, fmt.Errorf("invalid attribute description: %q", s)
+	}
+	return newAttributeDescription(s), nil
+}
+
+func newAttributeDescription(s string) AttributeDescription {
+	parts := strings.Split(s, ";")
+	return AttributeDescription{Type: parts[0], Options: parts[1:]}
+}
+
+// Description returns the attribute description of the attribute.
+func (a Attribute) Description() AttributeDescription {
+	return newAttributeDescription(a.Type)
+}
+
+// String returns the type and the options, separated by ";".
+func (d AttributeDescription) String() string {
+	return strings.Join(append([]string{d.Type}, d.Options...), ";")
+}
+
+// HasOption checks whether the attribute description has the given option.
+func (d AttributeDescription) HasOption(option string) bool {
+	for _, o := range d.Options {
+		if strings.EqualFold(o, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// Binary checks whether the attribute description has the "binary" option, as
+// defined in RFC 4522. The option only requests that the values are transferred
+// in their binary (BER) encoding, it does not make it another attribute: the
+// description is equal to the one without the option.
+func (d AttributeDescription) Binary() bool {
+	return d.HasOption("binary")
+}
+
+// Language returns the language tag of the first language tag option, as
+// defined in RFC 3866, in lower case. (e.g. "en-us" for "cn;lang-en-US") It is
+// empty if the attribute description has no language tag.
+func (d AttributeDescription) Language() string {
+	for _, o := range d.Options {
+		if tag, ok := languageTag(o); ok && tag != "" && !strings.HasSuffix(tag, "-") {
+			return tag
+		}
+	}
+	return ""
+}
+
+// languageTag returns the lower case tag or range of a language option.
+func languageTag(option string) (string, bool) {
+	option = strings.ToLower(option)
+	if !strings.HasPrefix(option, "lang-") {
+		return "", false
+	}
+	return strings.TrimPrefix(option, "lang-"), true
+}
+
+// Range returns the bounds of the "range=<low>-<high>" option that Active
+// Directory adds to the chunks of the values of large attributes. High is -1 if
+// the chunk is the last one. (e.g. "member;range=1500-*")
+func (d AttributeDescription) Range() (low, high int, ok bool) {
+	for _, o := range d.Options {
+		if len(o) < 6 || !strings.EqualFold(o[:6], "range=") {
+			continue
+		}
+		bounds := strings.SplitN(o[6:], "-", 2)
+		if len(bounds) != 2 {
+			return 0, 0, false
+		}
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return 0, 0, false
+		}
+		if bounds[1] == "*" {
+			return low, -1, true
+		}
+		high, err := strconv.Atoi(bounds[1])
+		if err != nil || high < low {
+			return 0, 0, false
+		}
+		return low, high, true
+	}
+	return 0, 0, false
+}
+
+// isRange checks whether the option is a range option.
+func isRange(option string) bool {
+	_, _, ok := AttributeDescription{Options: []string{option}}.Range()
+	return ok
+}
+
+// TaggingOptions returns the sorted, lower case options without the "binary" and
+// range options, which do not change the attribute the description refers to.
+func (d AttributeDescription) TaggingOptions() []string {
+	var options []string
+	for _, o := range d.Options {
+		if !strings.EqualFold(o, "binary") && !isRange(o) {
+			options = append(options, strings.ToLower(o))
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+// Equal checks whether the attribute descriptions refer to the same attribute:
+// they have the same type and the same options, the "binary" and range options
+// excluded.
+func (d AttributeDescription) Equal(other AttributeDescription) bool {
+	if !strings.EqualFold(d.Type, other.Type) {
+		return false
+	}
+	options, otherOptions := d.TaggingOptions(), other.TaggingOptions()
+	if len(options) != len(otherOptions) {
+		return false
+	}
+	for i := range options {
+		if options[i] != otherOptions[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches checks whether the attribute description is equal to, or a subtype
+// of, the other one. A subtype has all the options of its supertype, e.g.
+// "cn;lang-en" is a subtype of "cn". A language range option (e.g. "lang-en-")
+// matches all language tags that start with it, as well as the tag without the
+// trailing "-" ("lang-en"), as defined in RFC 3866.
+func (d AttributeDescription) Matches(other AttributeDescription) bool {
+	if !strings.EqualFold(d.Type, other.Type) {
+		return false
+	}
+	options := d.TaggingOptions()
+	for _, o := range other.TaggingOptions() {
+		if !matchesOption(options, o) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesOption(options []string, option string) bool {
+	lang, ok := languageTag(option)
+	// "lang-" is the empty range, that matches all language tags
+	ok = ok && strings.HasSuffix(option, "-")
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+		if tag, isTag := languageTag(o); ok && isTag && strings.HasPrefix(tag+"-", lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeRanges merges the chunks of the attributes of the record with a range
+// option, as exported by Active Directory for attributes with many values,
+// into attributes without the option. (e.g. "member;range=0-1499" and
+// "member;range=1500-*" become "member") The chunks of an attribute have to
+// cover all values, starting at 0 and ending with "*", and a chunk with a
+// bounded range has to contain exactly that many values. The merged values are
+// ordered by their range and take the place of the first chunk.
+//
+// Only the attributes of an Entry or an AddRecord can be merged. Delete and
+// modrdn records are left as is, as are modify records without range options.
+func MergeRanges(record Record) error {
+	var attributes []Attribute
+	switch r := record.(type) {
+	case *Entry:
+		attributes = r.Attributes
+	case *AddRecord:
+		attributes = r.Attributes
+	case *ModifyRecord:
+		for _, m := range r.Modifications {
+			if _, _, ok := newAttributeDescription(m.Attribute).Range(); ok {
+				return fmt.Errorf("%s: the chunks of a modification can not be merged", m.Attribute)
+			}
+		}
+		return nil
+	case *DeleteRecord, *ModDNRecord:
+		return nil
+	default:
+		return fmt.Errorf("can not merge the ranges of a %T", record)
+	}
+
+	type chunk struct {
+		typ       string
+		low, high int
+		values    []string
+	}
+	// the keys of the merged attributes, in the order they first appear
+	var order []string
+	chunks := make(map[string][]*chunk)
+	byType := make(map[string]*chunk)
+	types := make(map[string]string)
+	keys := make(map[int]string)
+	for i, a := range attributes {
+		d := a.Description()
+		low, high, ok := d.Range()
+		if !ok {
+			continue
+		}
+		var options []string
+		for _, o := range d.Options {
+			if !isRange(o) {
+				options = append(options, o)
+			}
+		}
+		merged := AttributeDescription{Type: d.Type, Options: options}.String()
+		k := strings.ToLower(merged)
+		c, ok := byType[strings.ToLower(a.Type)]
+		if !ok {
+			c = &chunk{typ: a.Type, low: low, high: high}
+			byType[strings.ToLower(a.Type)] = c
+			chunks[k] = append(chunks[k], c)
+		}
+		c.values = append(c.values, a.Value)
+		if _, ok := types[k]; !ok {
+			types[k] = merged
+			order = append(order, k)
+		}
+		keys[i] = k
+	}
+
+	for _, k := range order {
+		cs := chunks[k]
+		sort.Slice(cs, func(i, j int) bool { return cs[i].low < cs[j].low })
+		next := 0
+		for _, c := range cs {
+			if next < 0 {
+				return fmt.Errorf("%s: range after the last chunk", c.typ)
+			}
+			if c.low != next {
+				return fmt.Errorf("%s: expected a range starting at %d", c.typ, next)
+			}
+			if c.high < 0 {
+				next = -1
+				continue
+			}
+			if n := c.high - c.low + 1; len(c.values) != n {
+				return fmt.Errorf("%s: expected %d values, got %d", c.typ, n, len(c.values))
+			}
+			next = c.high + 1
+		}
+		if next != -1 {
+			return fmt.Errorf("%s: expected a range starting at %d and ending with \"*\"", cs[len(cs)-1].typ, next)
+		}
+	}
+
+	merged := make([]Attribute, 0, len(attributes))
+	for i, a := range attributes {
+		k, ok := keys[i]
+		if !ok {
+			merged = append(merged, a)
+			continue
+		}
+		for _, c := range chunks[k] {
+			for _, v := range c.values {
+				merged = append(merged, Attribute{Type: types[k], Value: v})
+			}
+		}
+		// the values are only added at the place of the first chunk
+		delete(chunks, k)
+	}
+	copy(attributes, merged)
+	return nil
+}
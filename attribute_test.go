@@ -0,0 +1,182 @@
+package ldif
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAttributeDescription(t *testing.T) {
+	for _, test := range []struct {
+		s        string
+		expected AttributeDescription
+	}{
+		{"cn", AttributeDescription{Type: "cn", Options: []string{}}},
+		{"cn;lang-en-US", AttributeDescription{Type: "cn", Options: []string{"lang-en-US"}}},
+		{"2.5.4.3;binary;x-1", AttributeDescription{Type: "2.5.4.3", Options: []string{"binary", "x-1"}}},
+		{"member;Range=0-1499", AttributeDescription{Type: "member", Options: []string{"Range=0-1499"}}},
+		{"member;range=1500-*", AttributeDescription{Type: "member", Options: []string{"range=1500-*"}}},
+	} {
+		d, err := ParseAttributeDescription(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(d, test.expected) {
+			t.Errorf("expected %#v, got %#v", test.expected, d)
+		}
+		if d.String() != test.s {
+			t.Errorf("expected %s, got %s", test.s, d.String())
+		}
+	}
+	for _, s := range []string{"", "cn;", ";lang-en", "cn;lang_en", "member;range=a-b", "member;x=1"} {
+		if _, err := ParseAttributeDescription(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestAttributeDescriptionOptions(t *testing.T) {
+	d := Attribute{Type: "userCertificate;Binary;lang-EN-us"}.Description()
+	if !d.Binary() || !d.HasOption("LANG-en-US") || d.HasOption("lang-en") {
+		t.Errorf("unexpected options: %v", d.Options)
+	}
+	if lang := d.Language(); lang != "en-us" {
+		t.Errorf("expected en-us, got %s", lang)
+	}
+	if lang := (AttributeDescription{Type: "cn", Options: []string{"lang-en-"}}).Language(); lang != "" {
+		t.Errorf("expected no language tag for a range, got %s", lang)
+	}
+
+	for _, test := range []struct {
+		low, high int
+		ok        bool
+		s         string
+	}{
+		{0, 1499, true, "member;range=0-1499"},
+		{1500, -1, true, "member;RANGE=1500-*"},
+		{0, 0, false, "member;range=10-5"},
+		{0, 0, false, "member"},
+	} {
+		low, high, ok := newAttributeDescription(test.s).Range()
+		if low != test.low || high != test.high || ok != test.ok {
+			t.Errorf("%s: expected %d, %d, %t, got %d, %d, %t", test.s, test.low, test.high, test.ok, low, high, ok)
+		}
+	}
+}
+
+func TestAttributeDescriptionEqual(t *testing.T) {
+	for _, test := range []struct {
+		a, b  string
+		equal bool
+	}{
+		{"cn", "CN", true},
+		{"cn;lang-en;x-a", "CN;X-A;Lang-EN", true},
+		{"userCertificate;binary", "usercertificate", true},
+		{"member;range=0-1499", "member", true},
+		{"cn;lang-en", "cn", false},
+		{"cn;lang-en", "cn;lang-de", false},
+		{"cn", "sn", false},
+	} {
+		if equal := newAttributeDescription(test.a).Equal(newAttributeDescription(test.b)); equal != test.equal {
+			t.Errorf("%s, %s: expected %t, got %t", test.a, test.b, test.equal, equal)
+		}
+	}
+}
+
+func TestAttributeDescriptionMatches(t *testing.T) {
+	for _, test := range []struct {
+		a, b    string
+		matches bool
+	}{
+		{"cn;lang-en", "cn", true},
+		{"cn;lang-en;x-a", "cn;x-a", true},
+		{"cn;lang-en", "cn;lang-en-", true},
+		{"cn;lang-en-US", "cn;lang-en-", true},
+		{"cn;lang-de", "cn;lang-", true},
+		{"cn;lang-enx", "cn;lang-en-", false},
+		{"cn;lang-en-us", "cn;lang-en", false},
+		{"cn", "cn;lang-", false},
+		{"cn", "cn;lang-en", false},
+		{"sn;lang-en", "cn", false},
+	} {
+		if matches := newAttributeDescription(test.a).Matches(newAttributeDescription(test.b)); matches != test.matches {
+			t.Errorf("%s, %s: expected %t, got %t", test.a, test.b, test.matches, matches)
+		}
+	}
+}
+
+func TestValuesOptions(t *testing.T) {
+	e := Entry{Attributes: []Attribute{
+		{Type: "userCertificate;binary", Value: "0"},
+		{Type: "cn;x-a;lang-en", Value: "Babs"},
+		{Type: "cn", Value: "Barbara"},
+	}}
+	if values := e.Values("usercertificate"); !reflect.DeepEqual(values, []string{"0"}) {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if values := e.Values("CN;LANG-EN;X-A"); !reflect.DeepEqual(values, []string{"Babs"}) {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	r := NewReader(strings.NewReader(`version: 1
+dn: cn=Group, dc=example, dc=com
+objectclass: group
+member;range=2-*: cn=C, dc=example, dc=com
+member;range=0-1: cn=A, dc=example, dc=com
+member;range=0-1: cn=B, dc=example, dc=com
+cn: Group
+`))
+	r.MergeRanges = true
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	expected := []Attribute{
+		{Type: "objectclass", Value: "group"},
+		{Type: "member", Value: "cn=A, dc=example, dc=com"},
+		{Type: "member", Value: "cn=B, dc=example, dc=com"},
+		{Type: "member", Value: "cn=C, dc=example, dc=com"},
+		{Type: "cn", Value: "Group"},
+	}
+	if attributes := r.Record().(*Entry).Attributes; !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("expected %v, got %v", expected, attributes)
+	}
+
+	for _, attributes := range [][]Attribute{
+		{{Type: "member;range=1-*", Value: "a"}},
+		{{Type: "member;range=0-1499", Value: "a"}},
+		{{Type: "member;range=0-0", Value: "a"}, {Type: "member;range=1-1", Value: "b"}},
+		{{Type: "member;range=0-1", Value: "a"}, {Type: "member;range=0-1", Value: "b"}, {Type: "member;range=3-*", Value: "c"}},
+		{{Type: "member;range=0-*", Value: "a"}, {Type: "member;range=1-*", Value: "b"}},
+		// a value is missing from the first chunk
+		{{Type: "member;range=0-2", Value: "a"}, {Type: "member;range=3-*", Value: "b"}},
+	} {
+		e := &Entry{Attributes: attributes}
+		if err := MergeRanges(e); err == nil {
+			t.Errorf("expected an error for %v", attributes)
+		}
+		if e.Attributes[0].Type == "member" {
+			t.Errorf("expected the attributes to be left as is: %v", e.Attributes)
+		}
+	}
+
+	// the attributes are checked in the order they appear
+	for i := 0; i < 10; i++ {
+		err := MergeRanges(&Entry{Attributes: []Attribute{
+			{Type: "member;range=1-*", Value: "a"},
+			{Type: "owner;range=1-*", Value: "b"},
+			{Type: "seeAlso;range=1-*", Value: "c"},
+		}})
+		if err == nil || !strings.HasPrefix(err.Error(), "member;range=1-*:") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := MergeRanges(&DeleteRecord{}); err != nil {
+		t.Error(err)
+	}
+	if err := MergeRanges(&ModifyRecord{Modifications: []Modification{{Type: ModAdd, Attribute: "member;range=0-*"}}}); err == nil {
+		t.Error("expected an error for a modification with a range option")
+	}
+}
@@ -0,0 +1,354 @@
+// Command ldif validates, formats, compares and converts LDIF files.
+//
+// Usage:
+//
+//	ldif validate [-lenient] [-json] [file...]
+//	ldif fmt [-width n] [file]
+//	ldif diff from.ldif to.ldif
+//	ldif apply content.ldif [changes.ldif...]
+//	ldif to-json [file]
+//	ldif from-json [file]
+//
+// Files are read from stdin if they are omitted or "-". The exit code is 0 on
+// success, 1 if a file is invalid (or, for diff, if the files differ) and 2 on
+// any other error.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/elimity-com/ldif"
+	"github.com/elimity-com/ldif/directory"
+)
+
+// The exit codes of the command.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+const usage = `usage: ldif <command> [arguments]
+
+commands:
+  validate [-lenient] [-json] [file...] check whether the files are valid LDIF
+  fmt [-width n] [file]                write the file in its canonical form
+  diff from.ldif to.ldif               write the changes from one file to the other
+  apply content.ldif [changes.ldif...] write the content after applying the changes
+  to-json [file]                       convert LDIF to JSON
+  from-json [file]                     convert JSON to LDIF
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command runs a subcommand, it returns its exit code.
+type command func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"validate":  validate,
+	"fmt":       format,
+	"diff":      diff,
+	"apply":     apply,
+	"to-json":   toJSON,
+	"from-json": fromJSON,
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ldif: unknown command %q\n%s", args[0], usage)
+		return exitError
+	}
+	return cmd(args[1:], stdin, stdout, stderr)
+}
+
+func validate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := newFlagSet("validate", stderr)
+	lenient := flags.Bool("lenient", false, "accept files without a version-spec")
+	asJSON := flags.Bool("json", false, "report errors as JSON objects, one per line")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	if !stdinOnce("validate", files, stderr) {
+		return exitError
+	}
+	// the files are all checked, the exit code is the one of the worst file
+	code := exitOK
+	for _, name := range files {
+		f, err := open(name, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "ldif: %v\n", err)
+			code = exitError
+			continue
+		}
+		r := ldif.NewReader(f)
+		r.Lenient = *lenient
+		for r.Next() {
+		}
+		f.Close()
+		if err := r.Err(); err != nil {
+			var perr *ldif.ParseError
+			if !errors.As(err, &perr) {
+				fmt.Fprintf(stderr, "ldif: %s: %v\n", name, err)
+				code = exitError
+				continue
+			}
+			if *asJSON {
+				reportJSON(stdout, name, perr)
+			} else {
+				fmt.Fprintf(stdout, "%s: %v\n", name, perr)
+			}
+			if code == exitOK {
+				code = exitInvalid
+			}
+		}
+	}
+	return code
+}
+
+// validationError is the JSON representation of a ParseError.
+type validationError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	DN      string `json:"dn,omitempty"`
+	Rule    string `json:"rule"`
+	Snippet string `json:"snippet,omitempty"`
+	Error   string `json:"error"`
+}
+
+func reportJSON(w io.Writer, name string, err *ldif.ParseError) {
+	data, _ := json.Marshal(validationError{
+		File:    name,
+		Line:    err.Line,
+		Column:  err.Column,
+		DN:      err.DN,
+		Rule:    err.Rule,
+		Snippet: err.Snippet,
+		Error:   err.Error(),
+	})
+	fmt.Fprintf(w, "%s\n", data)
+}
+
+func format(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := newFlagSet("fmt", stderr)
+	width := flags.Int("width", 76, "fold lines longer than `n` characters, 0 disables folding")
+	if err := flags.Parse(args); err != nil || 1 < flags.NArg() {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	records, code := parseFile(flags.Arg(0), stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+	return write(records, *width, stdout, stderr)
+}
+
+func diff(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := newFlagSet("diff", stderr)
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	if !stdinOnce("diff", flags.Args(), stderr) {
+		return exitError
+	}
+	var entries [2][]*ldif.Entry
+	for i, name := range flags.Args() {
+		records, code := parseFile(name, stdin, stderr)
+		if code != exitOK {
+			return code
+		}
+		for _, r := range records {
+			e, ok := r.(*ldif.Entry)
+			if !ok {
+				fmt.Fprintf(stderr, "ldif: %s: not an ldif-content file\n", name)
+				return exitError
+			}
+			entries[i] = append(entries[i], e)
+		}
+	}
+
+	changes, err := directory.Diff(entries[0], entries[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return exitError
+	}
+	if len(changes) == 0 {
+		return exitOK
+	}
+	records := make([]ldif.Record, len(changes))
+	for i, c := range changes {
+		records[i] = c
+	}
+	if code := write(records, 76, stdout, stderr); code != exitOK {
+		return code
+	}
+	return exitInvalid
+}
+
+func apply(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := newFlagSet("apply", stderr)
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	if !stdinOnce("apply", flags.Args(), stderr) {
+		return exitError
+	}
+	d := directory.New()
+	for _, name := range flags.Args() {
+		records, code := parseFile(name, stdin, stderr)
+		if code != exitOK {
+			return code
+		}
+		if err := d.Load(records); err != nil {
+			fmt.Fprintf(stderr, "ldif: %s: %v\n", name, err)
+			return exitInvalid
+		}
+	}
+	entries := d.Entries()
+	records := make([]ldif.Record, len(entries))
+	for i, e := range entries {
+		records[i] = e
+	}
+	return write(records, 76, stdout, stderr)
+}
+
+func toJSON(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := newFlagSet("to-json", stderr)
+	if err := flags.Parse(args); err != nil || 1 < flags.NArg() {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	records, code := parseFile(flags.Arg(0), stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+	data, err := ldif.MarshalJSON(records)
+	if err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return exitError
+	}
+	if _, err := fmt.Fprintf(stdout, "%s\n", data); err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func fromJSON(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := newFlagSet("from-json", stderr)
+	if err := flags.Parse(args); err != nil || 1 < flags.NArg() {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	data, err := readFile(flags.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return exitError
+	}
+	records, err := ldif.UnmarshalJSON(data)
+	if err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return exitInvalid
+	}
+	return write(records, 76, stdout, stderr)
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
+}
+
+// isStdin checks whether the file with the given name is read from stdin.
+func isStdin(name string) bool {
+	return name == "" || name == "-"
+}
+
+// stdinOnce checks that at most one of the files is read from stdin, since it
+// can only be read once. It prints the usage if it is read more than once.
+func stdinOnce(name string, files []string, stderr io.Writer) bool {
+	n := 0
+	for _, f := range files {
+		if isStdin(f) {
+			n++
+		}
+	}
+	if 1 < n {
+		fmt.Fprintf(stderr, "ldif: %s: can not read multiple files from stdin\n%s", name, usage)
+		return false
+	}
+	return true
+}
+
+// open opens the file with the given name, or returns stdin if the name is
+// empty or "-".
+func open(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if isStdin(name) {
+		return ioutil.NopCloser(stdin), nil
+	}
+	return os.Open(name)
+}
+
+func readFile(name string, stdin io.Reader) ([]byte, error) {
+	f, err := open(name, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// parseFile parses the LDIF file with the given name, it returns the exit code
+// if that fails.
+func parseFile(name string, stdin io.Reader, stderr io.Writer) ([]ldif.Record, int) {
+	data, err := readFile(name, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return nil, exitError
+	}
+	records, err := ldif.Parse(string(data))
+	if err != nil {
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(stderr, "ldif: %s: %v\n", name, err)
+		return nil, exitInvalid
+	}
+	return records, exitOK
+}
+
+func write(records []ldif.Record, width int, stdout, stderr io.Writer) int {
+	w := ldif.NewWriter(stdout)
+	w.Width = width
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			fmt.Fprintf(stderr, "ldif: %v\n", err)
+			return exitError
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(stderr, "ldif: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestValidate(t *testing.T) {
+	if code, out, _ := runCommand("", "validate", "../../testdata/example1.ldif", "../../testdata/example6.ldif"); code != exitOK || out != "" {
+		t.Errorf("valid files: exit code %d, output %q", code, out)
+	}
+
+	code, out, _ := runCommand("version: 1\ndn: cn=a\ncn a\n", "validate")
+	if code != exitInvalid || out != "-: line 3, column 3: expected attrval-spec (dn: cn=a): \"cn a\"\n" {
+		t.Errorf("invalid file: exit code %d, output %q", code, out)
+	}
+
+	code, out, _ = runCommand("version: 1\ndn: cn=a\ncn a\n", "validate", "-json")
+	if code != exitInvalid || !strings.HasPrefix(out, `{"file":"-","line":3,"column":3,"dn":"cn=a","rule":"attrval-spec"`) {
+		t.Errorf("invalid file: exit code %d, output %q", code, out)
+	}
+
+	if code, _, _ := runCommand("dn: cn=a\ncn: a\n", "validate", "-lenient"); code != exitOK {
+		t.Errorf("lenient: exit code %d", code)
+	}
+	if code, _, _ := runCommand("", "validate", "does-not-exist.ldif"); code != exitError {
+		t.Errorf("missing file: exit code %d", code)
+	}
+
+	// the files after a missing file are still checked
+	code, out, _ = runCommand("version: 1\ndn: cn=a\ncn a\n", "validate", "does-not-exist.ldif", "-")
+	if code != exitError || !strings.HasPrefix(out, "-: line 3, column 3") {
+		t.Errorf("missing file: exit code %d, output %q", code, out)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	code, out, _ := runCommand("version: 1\ndn:cn=a\ncn:: YQ==\n\n\n\ndn: cn=b\ncn: \n b\n", "fmt")
+	if code != exitOK || out != "version: 1\ndn: cn=a\ncn: a\n\ndn: cn=b\ncn: b\n" {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+}
+
+func TestDiffApply(t *testing.T) {
+	const from = "version: 1\ndn: cn=a\ncn: a\nsn: a\n\ndn: cn=b\ncn: b\n"
+	code, out, _ := runCommand(from, "diff", "-", "../../testdata/example1.ldif")
+	if code != exitInvalid || !strings.Contains(out, "changetype: delete") || !strings.Contains(out, "changetype: add") {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+	if code, out, _ := runCommand("", "diff", "../../testdata/example1.ldif", "../../testdata/example2.ldif"); code != exitInvalid || out == "" {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+	if code, out, _ := runCommand("", "diff", "../../testdata/example1.ldif", "../../testdata/example1.ldif"); code != exitOK || out != "" {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+
+	code, out, _ = runCommand("version: 1\ndn: cn=a\nchangetype: modify\nreplace: sn\nsn: b\n-\n", "apply", "../../testdata/example1.ldif", "-")
+	if code != exitInvalid {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+	code, out, _ = runCommand(from, "apply", "-")
+	if code != exitOK || out != "version: 1\ndn: cn=a\ncn: a\nsn: a\n\ndn: cn=b\ncn: b\n" {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	code, out, _ := runCommand("version: 1\ndn: cn=a\ncn: a\n", "to-json")
+	if code != exitOK {
+		t.Fatalf("exit code %d", code)
+	}
+	code, out, _ = runCommand(out, "from-json")
+	if code != exitOK || out != "version: 1\ndn: cn=a\ncn: a\n" {
+		t.Errorf("exit code %d, output %q", code, out)
+	}
+	if code, _, _ := runCommand(`[{"dn": "cn=a"}]`, "from-json"); code != exitInvalid {
+		t.Errorf("exit code %d", code)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"diff", "a.ldif"},
+		{"diff", "-", "-"},
+		{"apply", "-", "../../testdata/example1.ldif", "-"},
+		{"validate", "-", "-"},
+		{"fmt", "a.ldif", "b.ldif"},
+		{"to-json", "a.ldif", "b.ldif"},
+		{"from-json", "a.json", "b.json"},
+	} {
+		if code, _, errOut := runCommand("", args...); code != exitError || !strings.Contains(errOut, usage) {
+			t.Errorf("%v: exit code %d, error output %q", args, code, errOut)
+		}
+	}
+}
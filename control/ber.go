@@ -0,0 +1,94 @@
+package control
+
+import "fmt"
+
+// BER tags of the universal types that are used by the controls.
+const (
+	tagOctetString = 0x04
+	tagSequence    = 0x30
+)
+
+// element is a single BER encoded TLV (tag, length, value) triplet.
+type element struct {
+	tag   byte
+	value []byte
+}
+
+// readElement reads the first element of the data, it returns the element and
+// the remaining data. Only the definite length form and tag numbers below 31
+// are supported, which is all LDAP needs.
+func readElement(data []byte) (element, []byte, error) {
+	if len(data) < 2 {
+		return element{}, nil, fmt.Errorf("ber: unexpected end of data")
+	}
+	tag, length := data[0], int(data[1])
+	if tag&0x1F == 0x1F {
+		return element{}, nil, fmt.Errorf("ber: unsupported tag: %#x", tag)
+	}
+	data = data[2:]
+	if length&0x80 != 0 {
+		n := length & 0x7F
+		if n == 0 {
+			return element{}, nil, fmt.Errorf("ber: indefinite length is not supported")
+		}
+		if 4 < n || len(data) < n {
+			return element{}, nil, fmt.Errorf("ber: invalid length")
+		}
+		length = 0
+		for _, b := range data[:n] {
+			length = length<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if length < 0 || len(data) < length {
+		return element{}, nil, fmt.Errorf("ber: unexpected end of data")
+	}
+	return element{tag: tag, value: data[:length]}, data[length:], nil
+}
+
+// readSingle reads the data that consists of a single element.
+func readSingle(data []byte) (element, error) {
+	e, rest, err := readElement(data)
+	if err != nil {
+		return element{}, err
+	}
+	if len(rest) != 0 {
+		return element{}, fmt.Errorf("ber: %d bytes of trailing data", len(rest))
+	}
+	return e, nil
+}
+
+// readOnly reads the data that consists of a single element with the given tag.
+func readOnly(data []byte, tag byte) (element, error) {
+	e, err := readSingle(data)
+	if err != nil {
+		return element{}, err
+	}
+	if e.tag != tag {
+		return element{}, fmt.Errorf("ber: expected tag %#x, got %#x", tag, e.tag)
+	}
+	return e, nil
+}
+
+// children reads all elements of the value of a constructed element.
+func (e element) children() ([]element, error) {
+	var elements []element
+	for data := e.value; len(data) != 0; {
+		c, rest, err := readElement(data)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, c)
+		data = rest
+	}
+	return elements, nil
+}
+
+// boolean returns the value of a BOOLEAN element, BER allows any non-zero byte
+// for true.
+func (e element) boolean() (bool, error) {
+	if len(e.value) != 1 {
+		return false, fmt.Errorf("ber: invalid boolean")
+	}
+	return e.value[0] != 0, nil
+}
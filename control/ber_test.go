@@ -0,0 +1,62 @@
+package control
+
+import (
+	"bytes"
+	"testing"
+)
+
+// tlv returns the BER encoding of an element with the given tag and the
+// concatenated values, in the short length form.
+func tlv(tag byte, values ...[]byte) []byte {
+	value := bytes.Join(values, nil)
+	return append([]byte{tag, byte(len(value))}, value...)
+}
+
+func TestReadElement(t *testing.T) {
+	e, rest, err := readElement([]byte{0x04, 0x02, 'c', 'n', 0xFF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.tag != tagOctetString || string(e.value) != "cn" || !bytes.Equal(rest, []byte{0xFF}) {
+		t.Errorf("unexpected element: %#v, %x", e, rest)
+	}
+
+	// long length form
+	long := append([]byte{0x04, 0x82, 0x01, 0x00}, make([]byte, 256)...)
+	if e, err := readSingle(long); err != nil || len(e.value) != 256 {
+		t.Errorf("unexpected element: %v", err)
+	}
+
+	for _, data := range [][]byte{
+		{},
+		{0x04},
+		{0x04, 0x03, 'c', 'n'},
+		{0x30, 0x80, 0x00, 0x00},
+		{0x1F, 0x01, 0x00},
+		{0x04, 0x85, 0x01, 0x00, 0x00, 0x00, 0x00},
+	} {
+		if _, _, err := readElement(data); err == nil {
+			t.Errorf("expected an error for %x", data)
+		}
+	}
+	if _, err := readSingle([]byte{0x04, 0x00, 0x04, 0x00}); err == nil {
+		t.Error("expected an error for trailing data")
+	}
+	if _, err := readOnly([]byte{0x04, 0x00}, tagSequence); err == nil {
+		t.Error("expected an error for an unexpected tag")
+	}
+}
+
+func TestChildren(t *testing.T) {
+	e, err := readSingle(tlv(tagSequence, tlv(tagOctetString, []byte("cn")), tlv(tagOctetString, []byte("sn"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	children, err := e.children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 || string(children[0].value) != "cn" || string(children[1].value) != "sn" {
+		t.Errorf("unexpected children: %v", children)
+	}
+}
@@ -0,0 +1,233 @@
+// Package control decodes the LDAP controls of LDIF change records into typed
+// controls. Control values are BER encoded, the LDIF parser already decoded
+// their base64 representation.
+package control
+
+import (
+	"fmt"
+
+	"github.com/elimity-com/ldif"
+)
+
+// The OIDs of the controls that are known by the registry.
+const (
+	// RFC 4528
+	AssertionOID = "1.3.6.1.1.12"
+	// RFC 3296
+	ManageDsaITOID = "2.16.840.1.113730.3.4.2"
+	// Active Directory, also supported by OpenLDAP
+	PermissiveModifyOID = "1.2.840.113556.1.4.1413"
+	// RFC 4527
+	PostReadOID = "1.3.6.1.1.13.2"
+	PreReadOID  = "1.3.6.1.1.13.1"
+	// draft-zeilenga-ldap-relax
+	RelaxRulesOID = "1.3.6.1.4.1.4203.666.5.12"
+	// Active Directory
+	TreeDeleteOID = "1.2.840.113556.1.4.805"
+)
+
+// Control is a typed LDAP control.
+type Control interface {
+	// OID returns the object identifier of the control type.
+	OID() string
+}
+
+// Decoder decodes a control, of which the OID is the one the decoder is
+// registered for.
+type Decoder func(c ldif.Control) (Control, error)
+
+// Registry maps the OIDs of controls to their decoders.
+type Registry map[string]Decoder
+
+// known contains the decoders of the known controls, it is never modified.
+var known = Registry{
+	AssertionOID:        decodeAssertion,
+	ManageDsaITOID:      withoutValue(func(critical bool) Control { return &ManageDsaIT{Criticality: critical} }),
+	PermissiveModifyOID: withoutValue(func(critical bool) Control { return &PermissiveModify{Criticality: critical} }),
+	PostReadOID:         decodePostRead,
+	PreReadOID:          decodePreRead,
+	RelaxRulesOID:       withoutValue(func(critical bool) Control { return &RelaxRules{Criticality: critical} }),
+	TreeDeleteOID:       withoutValue(func(critical bool) Control { return &TreeDelete{Criticality: critical} }),
+}
+
+// NewRegistry returns a registry with the decoders of the known controls.
+// Applications can register the decoders of their own controls, which replace
+// the known ones for the same OID.
+func NewRegistry() Registry {
+	r := make(Registry, len(known))
+	for oid, d := range known {
+		r[oid] = d
+	}
+	return r
+}
+
+// Register registers the decoder for the given OID.
+func (r Registry) Register(oid string, d Decoder) {
+	r[oid] = d
+}
+
+// Decode decodes the control with the decoder that is registered for its OID.
+// Controls without a decoder are returned as *Unknown. A value that is
+// referenced by an url has to be resolved first, see ldif.ResolveURLs.
+func (r Registry) Decode(c ldif.Control) (Control, error) {
+	d, ok := r[c.OID]
+	if !ok {
+		return &Unknown{Control: c}, nil
+	}
+	if c.URL {
+		return nil, fmt.Errorf("control %s: the value is referenced by an url: %s", c.OID, c.Value)
+	}
+	control, err := d(c)
+	if err != nil {
+		return nil, fmt.Errorf("control %s: %v", c.OID, err)
+	}
+	return control, nil
+}
+
+// DecodeAll decodes all controls, as in the Controls of a change record.
+func (r Registry) DecodeAll(controls []ldif.Control) ([]Control, error) {
+	decoded := make([]Control, 0, len(controls))
+	for _, c := range controls {
+		control, err := r.Decode(c)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, control)
+	}
+	return decoded, nil
+}
+
+// Decode decodes the control if it is one of the known controls.
+func Decode(c ldif.Control) (Control, error) {
+	return known.Decode(c)
+}
+
+// Unknown is a control without a registered decoder.
+type Unknown struct {
+	Control ldif.Control
+}
+
+// OID returns the OID of the control.
+func (c *Unknown) OID() string { return c.Control.OID }
+
+// TreeDelete deletes an entry with all its descendants.
+type TreeDelete struct {
+	Criticality bool
+}
+
+// OID returns TreeDeleteOID.
+func (c *TreeDelete) OID() string { return TreeDeleteOID }
+
+// ManageDsaIT manages referral objects as normal entries.
+type ManageDsaIT struct {
+	Criticality bool
+}
+
+// OID returns ManageDsaITOID.
+func (c *ManageDsaIT) OID() string { return ManageDsaITOID }
+
+// RelaxRules relaxes schema and data constraints, e.g. allows to change
+// NO-USER-MODIFICATION attributes.
+type RelaxRules struct {
+	Criticality bool
+}
+
+// OID returns RelaxRulesOID.
+func (c *RelaxRules) OID() string { return RelaxRulesOID }
+
+// PermissiveModify makes the modifications that add existing values or delete
+// missing values succeed.
+type PermissiveModify struct {
+	Criticality bool
+}
+
+// OID returns PermissiveModifyOID.
+func (c *PermissiveModify) OID() string { return PermissiveModifyOID }
+
+// Assertion only applies the change if the entry matches the filter.
+type Assertion struct {
+	Criticality bool
+	// Filter is the string representation of the filter, as defined in
+	// RFC 4515. (e.g. "(&(objectClass=person)(sn=Jensen))")
+	Filter string
+}
+
+// OID returns AssertionOID.
+func (c *Assertion) OID() string { return AssertionOID }
+
+// PreRead returns the given attributes of the entry before the change.
+type PreRead struct {
+	Criticality bool
+	Attributes  []string
+}
+
+// OID returns PreReadOID.
+func (c *PreRead) OID() string { return PreReadOID }
+
+// PostRead returns the given attributes of the entry after the change.
+type PostRead struct {
+	Criticality bool
+	Attributes  []string
+}
+
+// OID returns PostReadOID.
+func (c *PostRead) OID() string { return PostReadOID }
+
+// withoutValue returns a decoder for the controls that have no value.
+func withoutValue(newControl func(critical bool) Control) Decoder {
+	return func(c ldif.Control) (Control, error) {
+		if c.Value != "" {
+			return nil, fmt.Errorf("unexpected control value")
+		}
+		return newControl(c.Criticality), nil
+	}
+}
+
+// decodeAssertion decodes a control of which the value is a Filter.
+func decodeAssertion(c ldif.Control) (Control, error) {
+	e, err := readSingle([]byte(c.Value))
+	if err != nil {
+		return nil, err
+	}
+	filter, err := decodeFilter(e)
+	if err != nil {
+		return nil, err
+	}
+	return &Assertion{Criticality: c.Criticality, Filter: filter}, nil
+}
+
+func decodePreRead(c ldif.Control) (Control, error) {
+	attributes, err := attributeSelection(c.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &PreRead{Criticality: c.Criticality, Attributes: attributes}, nil
+}
+
+func decodePostRead(c ldif.Control) (Control, error) {
+	attributes, err := attributeSelection(c.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &PostRead{Criticality: c.Criticality, Attributes: attributes}, nil
+}
+
+// attributeSelection decodes an AttributeSelection, a SEQUENCE OF LDAPString.
+func attributeSelection(value string) ([]string, error) {
+	e, err := readOnly([]byte(value), tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	children, err := e.children()
+	if err != nil {
+		return nil, err
+	}
+	attributes := make([]string, len(children))
+	for i, a := range children {
+		if a.tag != tagOctetString {
+			return nil, fmt.Errorf("ber: expected tag %#x, got %#x", tagOctetString, a.tag)
+		}
+		attributes[i] = string(a.value)
+	}
+	return attributes, nil
+}
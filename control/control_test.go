@@ -0,0 +1,100 @@
+package control
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/elimity-com/ldif"
+)
+
+func TestDecode(t *testing.T) {
+	selection := string(tlv(tagSequence, tlv(tagOctetString, []byte("cn")), tlv(tagOctetString, []byte("modifyTimestamp"))))
+	for _, test := range []struct {
+		control  ldif.Control
+		expected Control
+	}{
+		{ldif.Control{OID: TreeDeleteOID, Criticality: true}, &TreeDelete{Criticality: true}},
+		{ldif.Control{OID: ManageDsaITOID}, &ManageDsaIT{}},
+		{ldif.Control{OID: RelaxRulesOID, Criticality: true}, &RelaxRules{Criticality: true}},
+		{ldif.Control{OID: PermissiveModifyOID}, &PermissiveModify{}},
+		{
+			ldif.Control{OID: AssertionOID, Criticality: true, Value: string(ava(filterEqualityMatch, "sn", "Jensen"))},
+			&Assertion{Criticality: true, Filter: "(sn=Jensen)"},
+		},
+		{ldif.Control{OID: PreReadOID, Value: selection}, &PreRead{Attributes: []string{"cn", "modifyTimestamp"}}},
+		{ldif.Control{OID: PostReadOID, Value: string(tlv(tagSequence))}, &PostRead{Attributes: []string{}}},
+		{ldif.Control{OID: "1.2.3", Value: "x"}, &Unknown{Control: ldif.Control{OID: "1.2.3", Value: "x"}}},
+	} {
+		c, err := Decode(test.control)
+		if err != nil {
+			t.Errorf("%s: %v", test.control.OID, err)
+			continue
+		}
+		if !reflect.DeepEqual(c, test.expected) {
+			t.Errorf("expected %#v, got %#v", test.expected, c)
+		}
+		if c.OID() != test.control.OID {
+			t.Errorf("expected %s, got %s", test.control.OID, c.OID())
+		}
+	}
+
+	for _, c := range []ldif.Control{
+		{OID: TreeDeleteOID, Value: "x"},
+		{OID: AssertionOID},
+		{OID: AssertionOID, Value: string(tlv(tagOctetString))},
+		{OID: PreReadOID, Value: string(tlv(tagOctetString))},
+		{OID: PostReadOID, Value: string(tlv(tagSequence, tlv(tagSequence)))},
+		{OID: PostReadOID, Value: "file:///postread", URL: true},
+	} {
+		if _, err := Decode(c); err == nil {
+			t.Errorf("expected an error for %#v", c)
+		}
+	}
+}
+
+// example7.ldif deletes an entry with the tree delete control.
+func TestDecodeExample(t *testing.T) {
+	raw, err := ioutil.ReadFile("../testdata/example7.ldif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ldif.Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	controls, err := NewRegistry().DecodeAll(records[0].(ldif.ChangeRecord).Header().Controls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []Control{&TreeDelete{Criticality: true}}; !reflect.DeepEqual(controls, expected) {
+		t.Errorf("expected %v, got %v", expected, controls)
+	}
+}
+
+// sortKey is a control that is not known by the registry.
+type sortKey struct {
+	key string
+}
+
+func (c *sortKey) OID() string { return "1.2.840.113556.1.4.473" }
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	r.Register("1.2.840.113556.1.4.473", func(c ldif.Control) (Control, error) {
+		return &sortKey{key: c.Value}, nil
+	})
+	controls, err := r.DecodeAll([]ldif.Control{
+		{OID: "1.2.840.113556.1.4.473", Value: "cn"},
+		{OID: TreeDeleteOID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []Control{&sortKey{key: "cn"}, &TreeDelete{}}; !reflect.DeepEqual(controls, expected) {
+		t.Errorf("expected %v, got %v", expected, controls)
+	}
+	if _, ok := known["1.2.840.113556.1.4.473"]; ok {
+		t.Error("expected the known controls to be left as is")
+	}
+}
@@ -0,0 +1,172 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RFC 4511: 4.5.1. Search Request Definition
+// The context-specific tags of the Filter choices.
+const (
+	filterAnd             = 0xA0
+	filterOr              = 0xA1
+	filterNot             = 0xA2
+	filterEqualityMatch   = 0xA3
+	filterSubstrings      = 0xA4
+	filterGreaterOrEqual  = 0xA5
+	filterLessOrEqual     = 0xA6
+	filterPresent         = 0x87
+	filterApproxMatch     = 0xA8
+	filterExtensibleMatch = 0xA9
+)
+
+// decodeFilter returns the string representation, as defined in RFC 4515, of a
+// BER encoded Filter.
+func decodeFilter(e element) (string, error) {
+	switch e.tag {
+	case filterAnd, filterOr:
+		children, err := e.children()
+		if err != nil {
+			return "", err
+		}
+		op := "&"
+		if e.tag == filterOr {
+			op = "|"
+		}
+		var b strings.Builder
+		b.WriteString("(" + op)
+		for _, c := range children {
+			f, err := decodeFilter(c)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(f)
+		}
+		b.WriteString(")")
+		return b.String(), nil
+	case filterNot:
+		c, err := readSingle(e.value)
+		if err != nil {
+			return "", err
+		}
+		f, err := decodeFilter(c)
+		if err != nil {
+			return "", err
+		}
+		return "(!" + f + ")", nil
+	case filterEqualityMatch, filterGreaterOrEqual, filterLessOrEqual, filterApproxMatch:
+		children, err := e.children()
+		if err != nil {
+			return "", err
+		}
+		if len(children) != 2 || children[0].tag != tagOctetString || children[1].tag != tagOctetString {
+			return "", fmt.Errorf("invalid attribute value assertion")
+		}
+		op := map[byte]string{
+			filterEqualityMatch:  "=",
+			filterGreaterOrEqual: ">=",
+			filterLessOrEqual:    "<=",
+			filterApproxMatch:    "~=",
+		}[e.tag]
+		return "(" + string(children[0].value) + op + escapeFilterValue(children[1].value) + ")", nil
+	case filterSubstrings:
+		return decodeSubstrings(e)
+	case filterPresent:
+		return "(" + string(e.value) + "=*)", nil
+	case filterExtensibleMatch:
+		return decodeExtensibleMatch(e)
+	default:
+		return "", fmt.Errorf("unknown filter: %#x", e.tag)
+	}
+}
+
+// decodeSubstrings decodes a SubstringFilter, of which the substrings are
+// tagged initial [0], any [1] and final [2].
+func decodeSubstrings(e element) (string, error) {
+	children, err := e.children()
+	if err != nil {
+		return "", err
+	}
+	if len(children) != 2 || children[0].tag != tagOctetString || children[1].tag != tagSequence {
+		return "", fmt.Errorf("invalid substring filter")
+	}
+	substrings, err := children[1].children()
+	if err != nil {
+		return "", err
+	}
+	if len(substrings) == 0 {
+		return "", fmt.Errorf("invalid substring filter: no substrings")
+	}
+	var initial, final string
+	anys := []string{""}
+	for i, s := range substrings {
+		switch {
+		case s.tag == 0x80 && i == 0:
+			initial = escapeFilterValue(s.value)
+		case s.tag == 0x81:
+			anys = append(anys, escapeFilterValue(s.value))
+		case s.tag == 0x82 && i == len(substrings)-1:
+			final = escapeFilterValue(s.value)
+		default:
+			return "", fmt.Errorf("invalid substring filter: unexpected substring %#x", s.tag)
+		}
+	}
+	anys = append(anys, "")
+	return "(" + string(children[0].value) + "=" + initial + strings.Join(anys, "*") + final + ")", nil
+}
+
+// decodeExtensibleMatch decodes a MatchingRuleAssertion, with an optional
+// matchingRule [1], type [2], a matchValue [3] and dnAttributes [4].
+func decodeExtensibleMatch(e element) (string, error) {
+	children, err := e.children()
+	if err != nil {
+		return "", err
+	}
+	var rule, typ, value string
+	var dn, hasValue bool
+	for _, c := range children {
+		switch c.tag {
+		case 0x81:
+			rule = string(c.value)
+		case 0x82:
+			typ = string(c.value)
+		case 0x83:
+			value, hasValue = escapeFilterValue(c.value), true
+		case 0x84:
+			if dn, err = c.boolean(); err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("invalid extensible match: unexpected element %#x", c.tag)
+		}
+	}
+	if !hasValue || (rule == "" && typ == "") {
+		return "", fmt.Errorf("invalid extensible match")
+	}
+	s := "(" + typ
+	if dn {
+		s += ":dn"
+	}
+	if rule != "" {
+		s += ":" + rule
+	}
+	return s + ":=" + value + ")", nil
+}
+
+// escapeFilterValue escapes "*", "(", ")", "\", NUL and the bytes that are not
+// part of valid UTF-8 as "\" followed by two hexadecimal digits.
+func escapeFilterValue(value []byte) string {
+	var b strings.Builder
+	for len(value) != 0 {
+		r, size := utf8.DecodeRune(value)
+		if r == utf8.RuneError && size <= 1 || strings.ContainsRune("*()\\\x00", r) {
+			fmt.Fprintf(&b, "\\%02x", value[0])
+			value = value[1:]
+			continue
+		}
+		b.Write(value[:size])
+		value = value[size:]
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package control
+
+import "testing"
+
+// ava returns the encoding of an AttributeValueAssertion with the given tag.
+func ava(tag byte, typ, value string) []byte {
+	return tlv(tag, tlv(tagOctetString, []byte(typ)), tlv(tagOctetString, []byte(value)))
+}
+
+func TestDecodeFilter(t *testing.T) {
+	for _, test := range []struct {
+		ber      []byte
+		expected string
+	}{
+		{tlv(filterPresent, []byte("objectClass")), "(objectClass=*)"},
+		{ava(filterEqualityMatch, "cn", "Babs Jensen"), "(cn=Babs Jensen)"},
+		{ava(filterGreaterOrEqual, "uidNumber", "1000"), "(uidNumber>=1000)"},
+		{ava(filterLessOrEqual, "uidNumber", "2000"), "(uidNumber<=2000)"},
+		{ava(filterApproxMatch, "sn", "Jensen"), "(sn~=Jensen)"},
+		{ava(filterEqualityMatch, "description", "(*)\\\x00\xff"), `(description=\28\2a\29\5c\00\ff)`},
+		{ava(filterEqualityMatch, "sn", "Lučić"), "(sn=Lučić)"},
+		{
+			tlv(filterAnd, tlv(filterPresent, []byte("objectClass")), tlv(filterNot, ava(filterEqualityMatch, "sn", "Jensen"))),
+			"(&(objectClass=*)(!(sn=Jensen)))",
+		},
+		{
+			tlv(filterOr, ava(filterEqualityMatch, "cn", "a"), ava(filterEqualityMatch, "cn", "b")),
+			"(|(cn=a)(cn=b))",
+		},
+		{
+			tlv(filterSubstrings, tlv(tagOctetString, []byte("cn")), tlv(tagSequence, tlv(0x80, []byte("Ba")), tlv(0x81, []byte("b")), tlv(0x82, []byte("s")))),
+			"(cn=Ba*b*s)",
+		},
+		{
+			tlv(filterSubstrings, tlv(tagOctetString, []byte("cn")), tlv(tagSequence, tlv(0x81, []byte("abs")))),
+			"(cn=*abs*)",
+		},
+		{
+			tlv(filterSubstrings, tlv(tagOctetString, []byte("cn")), tlv(tagSequence, tlv(0x82, []byte("*")))),
+			`(cn=*\2a)`,
+		},
+		{
+			tlv(filterExtensibleMatch, tlv(0x81, []byte("caseExactMatch")), tlv(0x82, []byte("cn")), tlv(0x83, []byte("Babs")), tlv(0x84, []byte{0xFF})),
+			"(cn:dn:caseExactMatch:=Babs)",
+		},
+		{
+			tlv(filterExtensibleMatch, tlv(0x82, []byte("cn")), tlv(0x83, []byte("Babs"))),
+			"(cn:=Babs)",
+		},
+	} {
+		e, err := readSingle(test.ber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter, err := decodeFilter(e)
+		if err != nil {
+			t.Errorf("%s: %v", test.expected, err)
+			continue
+		}
+		if filter != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, filter)
+		}
+	}
+
+	for _, ber := range [][]byte{
+		tlv(0xAA),
+		tlv(filterEqualityMatch, tlv(tagOctetString, []byte("cn"))),
+		tlv(filterSubstrings, tlv(tagOctetString, []byte("cn")), tlv(tagSequence)),
+		tlv(filterSubstrings, tlv(tagOctetString, []byte("cn")), tlv(tagSequence, tlv(0x81, []byte("a")), tlv(0x80, []byte("b")))),
+		tlv(filterExtensibleMatch, tlv(0x83, []byte("Babs"))),
+		tlv(filterNot, ava(filterEqualityMatch, "cn", "a"), ava(filterEqualityMatch, "cn", "b")),
+	} {
+		e, err := readSingle(ber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filter, err := decodeFilter(e); err == nil {
+			t.Errorf("expected an error for %x, got %s", ber, filter)
+		}
+	}
+}
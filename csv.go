@@ -0,0 +1,153 @@
+package ldif
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/elimity-com/ldif/dn3"
+)
+
+// WriteCSV writes the entries as CSV. The first column is the distinguished
+// name, followed by one column per attribute type, in the order they appear in
+// the entries. The values of multi-valued attributes are joined by the given
+// delimiter, so values can not contain the delimiter, since ReadCSV would split
+// them. Nothing is written if any of the entries can not be written.
+func WriteCSV(w io.Writer, entries []*Entry, delimiter string) error {
+	header := []string{"dn"}
+	columns := make(map[string]int)
+	for _, e := range entries {
+		for _, a := range e.Attributes {
+			if strings.EqualFold(a.Type, "dn") {
+				return fmt.Errorf("%s: an attribute named dn can not be written", e.DN)
+			}
+			if _, ok := columns[strings.ToLower(a.Type)]; !ok {
+				columns[strings.ToLower(a.Type)] = len(header)
+				header = append(header, a.Type)
+			}
+		}
+	}
+
+	rows := [][]string{header}
+	for _, e := range entries {
+		row := make([]string, len(header))
+		row[0] = e.DN
+		for _, a := range e.Attributes {
+			if a.URL {
+				return fmt.Errorf("%s: %s: values that are referenced by an url can not be written", e.DN, a.Type)
+			}
+			if delimiter != "" && strings.Contains(a.Value, delimiter) {
+				return fmt.Errorf("%s: %s: value contains the delimiter %q", e.DN, a.Type, delimiter)
+			}
+			i := columns[strings.ToLower(a.Type)]
+			if row[i] != "" {
+				if delimiter == "" {
+					return fmt.Errorf("%s: %s: multiple values without a delimiter", e.DN, a.Type)
+				}
+				row[i] += delimiter
+			}
+			row[i] += a.Value
+		}
+		rows = append(rows, row)
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// placeholder matches the placeholders of a DN template, as in "{uid}".
+var placeholder = regexp.MustCompile(`{([^{}]*)}`)
+
+// ReadCSV reads the rows of CSV as add records. The first row contains the
+// attribute types, the values of multi-valued attributes are split by the given
+// delimiter and empty values are omitted.
+//
+// The distinguished names are created from the template, of which the
+// placeholders are replaced by the (escaped) value of the column with the same
+// name, as in "uid={uid},ou=People,dc=example,dc=com". If the template is
+// empty, the "dn" column is used instead. The "dn" column is never used as an
+// attribute.
+func ReadCSV(r io.Reader, template, delimiter string) ([]*AddRecord, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, typ := range header {
+		if _, ok := columns[strings.ToLower(typ)]; ok {
+			return nil, fmt.Errorf("duplicate column: %q", typ)
+		}
+		columns[strings.ToLower(typ)] = i
+	}
+	if _, ok := columns["dn"]; !ok && template == "" {
+		return nil, fmt.Errorf("missing dn column or template")
+	}
+	for _, m := range placeholder.FindAllStringSubmatch(template, -1) {
+		if _, ok := columns[strings.ToLower(m[1])]; !ok {
+			return nil, fmt.Errorf("template refers to a missing column: %q", m[1])
+		}
+	}
+
+	var records []*AddRecord
+	for row := 2; ; row++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		dn, err := templateDN(template, delimiter, columns, fields)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		if _, err := dn3.ParseDN(dn); err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		record := &AddRecord{Change: Change{DN: dn}}
+		for i, field := range fields {
+			if strings.EqualFold(header[i], "dn") || field == "" {
+				continue
+			}
+			values := []string{field}
+			if delimiter != "" {
+				values = strings.Split(field, delimiter)
+			}
+			for _, v := range values {
+				if v != "" {
+					record.Attributes = append(record.Attributes, Attribute{Type: header[i], Value: v})
+				}
+			}
+		}
+		if len(record.Attributes) == 0 {
+			return nil, fmt.Errorf("row %d: %s: no attributes", row, dn)
+		}
+		records = append(records, record)
+	}
+}
+
+// templateDN returns the distinguished name of the row.
+func templateDN(template, delimiter string, columns map[string]int, row []string) (string, error) {
+	if template == "" {
+		dn := row[columns["dn"]]
+		if dn == "" {
+			return "", fmt.Errorf("empty dn")
+		}
+		return dn, nil
+	}
+	var err error
+	dn := placeholder.ReplaceAllStringFunc(template, func(p string) string {
+		column := p[1 : len(p)-1]
+		value := row[columns[strings.ToLower(column)]]
+		if value == "" && err == nil {
+			err = fmt.Errorf("empty value for %s", column)
+		}
+		if delimiter != "" && strings.Contains(value, delimiter) && err == nil {
+			err = fmt.Errorf("multiple values for %s", column)
+		}
+		return dn3.EscapeValue(value)
+	})
+	return dn, err
+}
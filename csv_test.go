@@ -0,0 +1,113 @@
+package ldif
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/example1.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []*Entry
+	for _, r := range records {
+		entries = append(entries, r.(*Entry))
+	}
+
+	var buf strings.Builder
+	if err := WriteCSV(&buf, entries, "|"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `dn,objectclass,cn,sn,uid,telephonenumber,description
+"cn=Barbara Jensen, ou=Product Development, dc=airius, dc=com",top|person|organizationalPerson,Barbara Jensen|Barbara J Jensen|Babs Jensen,Jensen,bjensen,+1 408 555 1212,A big sailing fan.
+"cn=Bjorn Jensen, ou=Accounting, dc=airius, dc=com",top|person|organizationalPerson,Bjorn Jensen,Jensen,,+1 408 555 1212,
+`; buf.String() != expected {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+
+	for _, e := range []*Entry{
+		{DN: "cn=a", Attributes: []Attribute{{Type: "cn", Value: "a|b"}}},
+		{DN: "cn=a", Attributes: []Attribute{{Type: "cn", Value: "a"}, {Type: "cn", Value: "b|c"}}},
+		{DN: "cn=a", Attributes: []Attribute{{Type: "jpegphoto", Value: "file:///a.jpg", URL: true}}},
+		{DN: "cn=a", Attributes: []Attribute{{Type: "DN", Value: "cn=b"}}},
+	} {
+		// nothing is written, not even the valid entries before the invalid one
+		buf.Reset()
+		if err := WriteCSV(&buf, append(entries, e), "|"); err == nil {
+			t.Errorf("no error for %v", e)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("unexpected csv for %v:\n%s", e, buf.String())
+		}
+	}
+	if err := WriteCSV(ioutil.Discard, entries, ""); err == nil {
+		t.Error("no error for multiple values without a delimiter")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	records, err := ReadCSV(strings.NewReader(`uid,cn,objectClass,mail
+bjensen,"Jensen, Barbara",top|person|inetOrgPerson,
+#fiona , Fiona Jensen,top|person|inetOrgPerson,fiona@example.com|fj@example.com
+`), "uid={uid},ou=People,dc=example,dc=com", "|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []*AddRecord{
+		{
+			Change: Change{DN: "uid=bjensen,ou=People,dc=example,dc=com"},
+			Attributes: []Attribute{
+				{Type: "uid", Value: "bjensen"},
+				{Type: "cn", Value: "Jensen, Barbara"},
+				{Type: "objectClass", Value: "top"},
+				{Type: "objectClass", Value: "person"},
+				{Type: "objectClass", Value: "inetOrgPerson"},
+			},
+		},
+		{
+			Change: Change{DN: `uid=\#fiona\ ,ou=People,dc=example,dc=com`},
+			Attributes: []Attribute{
+				{Type: "uid", Value: "#fiona "},
+				{Type: "cn", Value: " Fiona Jensen"},
+				{Type: "objectClass", Value: "top"},
+				{Type: "objectClass", Value: "person"},
+				{Type: "objectClass", Value: "inetOrgPerson"},
+				{Type: "mail", Value: "fiona@example.com"},
+				{Type: "mail", Value: "fj@example.com"},
+			},
+		},
+	}; !reflect.DeepEqual(records, expected) {
+		t.Errorf("unexpected records: %v", records)
+	}
+
+	records, err = ReadCSV(strings.NewReader("dn,cn\n\"cn=a,dc=com\",a\n"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0].DN != "cn=a,dc=com" || !reflect.DeepEqual(records[0].Attributes, []Attribute{{Type: "cn", Value: "a"}}) {
+		t.Errorf("unexpected record: %v", records[0])
+	}
+}
+
+func TestReadCSVInvalid(t *testing.T) {
+	for _, test := range []struct {
+		csv, template string
+	}{
+		{"cn\na\n", ""},
+		{"cn\na\n", "uid={uid},dc=com"},
+		{"uid,cn\n,a\n", "uid={uid},dc=com"},
+		{"uid,cn\na|b,a\n", "uid={uid},dc=com"},
+		{"uid\na\n", "uid={uid},,dc=com"},
+		{"dn,cn\n,a\n", ""},
+		{"uid,cn\na,a,a\n", "uid={uid}"},
+		{"uid,cn,CN\na,a,b\n", "uid={uid}"},
+	} {
+		if _, err := ReadCSV(strings.NewReader(test.csv), test.template, "|"); err == nil {
+			t.Errorf("no error for %q and %q", test.csv, test.template)
+		}
+	}
+}
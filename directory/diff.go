@@ -0,0 +1,245 @@
+package directory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elimity-com/ldif"
+	"github.com/elimity-com/ldif/dn3"
+)
+
+// Diff returns the change records that, applied to a directory with the from
+// entries, result in a directory with the to entries.
+//
+// An entry that only exists in from is renamed, instead of being deleted, if an
+// entry that only exists in to has the same attributes after the rename. The
+// children of a renamed entry are moved along. Other modifications use as few
+// mod-specs as possible, an attribute of which all values change is replaced.
+//
+// The records are ordered so that they can be applied: renames first, parents
+// are added before their children and children are deleted before their
+// parents.
+func Diff(from, to []*ldif.Entry) ([]ldif.ChangeRecord, error) {
+	fromEntries, err := newEntries(from)
+	if err != nil {
+		return nil, err
+	}
+	toEntries, err := newEntries(to)
+	if err != nil {
+		return nil, err
+	}
+	fromKeys, toKeys := keys(fromEntries), keys(toEntries)
+
+	var changes []ldif.ChangeRecord
+	for _, e := range byDepth(fromEntries) {
+		if _, ok := toKeys[key(e.dn)]; ok {
+			continue
+		}
+		for _, t := range toEntries {
+			if _, ok := fromKeys[key(t.dn)]; ok {
+				continue
+			}
+			record, ok := rename(e, t, fromKeys, toKeys)
+			if !ok {
+				continue
+			}
+			oldDN := e.dn
+			var moved []*entry
+			for _, c := range fromEntries {
+				if c == e || c.dn.IsDescendantOf(oldDN) {
+					delete(fromKeys, key(c.dn))
+					c.dn, _ = c.dn.Rebase(oldDN, t.dn)
+					moved = append(moved, c)
+				}
+			}
+			for _, c := range moved {
+				fromKeys[key(c.dn)] = c
+			}
+			e.entry = t.entry
+			changes = append(changes, record)
+			break
+		}
+	}
+
+	for _, t := range byDepth(toEntries) {
+		if _, ok := fromKeys[key(t.dn)]; !ok {
+			changes = append(changes, &ldif.AddRecord{
+				Change:     ldif.Change{DN: t.entry.DN},
+				Attributes: t.entry.Attributes,
+			})
+		}
+	}
+	for _, t := range toEntries {
+		e, ok := fromKeys[key(t.dn)]
+		if !ok {
+			continue
+		}
+		if m := modifications(e.entry.Attributes, t.entry.Attributes); len(m) != 0 {
+			changes = append(changes, &ldif.ModifyRecord{
+				Change:        ldif.Change{DN: t.entry.DN},
+				Modifications: m,
+			})
+		}
+	}
+	deleted := byDepth(fromEntries)
+	for i := len(deleted) - 1; 0 <= i; i-- {
+		if _, ok := toKeys[key(deleted[i].dn)]; !ok {
+			changes = append(changes, &ldif.DeleteRecord{
+				Change: ldif.Change{DN: deleted[i].dn.String()},
+			})
+		}
+	}
+	return changes, nil
+}
+
+// rename returns the modrdn record that renames the from entry to the to entry,
+// if they have the same attributes after the rename. The new superior of the
+// entry can not be an entry that still needs to be added.
+func rename(from, to *entry, fromKeys, toKeys map[string]*entry) (*ldif.ModDNRecord, bool) {
+	if from.dn.Depth() == 0 || to.dn.Depth() == 0 {
+		return nil, false
+	}
+	parent, newParent := from.dn.Parent(), to.dn.Parent()
+	if _, ok := toKeys[key(newParent)]; ok {
+		if _, ok := fromKeys[key(newParent)]; !ok {
+			return nil, false
+		}
+	}
+	if newParent.Equal(from.dn) || newParent.IsDescendantOf(from.dn) {
+		return nil, false
+	}
+
+	for _, deleteOldRDN := range []bool{true, false} {
+		attributes := from.entry.Attributes
+		if deleteOldRDN {
+			for _, a := range from.dn.RDN().Attributes {
+				if i := indexOf(attributes, a.Type, a.Value); i >= 0 {
+					attributes = append(attributes[:i:i], attributes[i+1:]...)
+				}
+			}
+		}
+		for _, a := range to.dn.RDN().Attributes {
+			if indexOf(attributes, a.Type, a.Value) < 0 {
+				attributes = append(attributes, ldif.Attribute{Type: a.Type, Value: a.Value})
+			}
+		}
+		if len(modifications(attributes, to.entry.Attributes)) != 0 {
+			continue
+		}
+
+		record := &ldif.ModDNRecord{
+			Change:       ldif.Change{DN: from.dn.String()},
+			NewRDN:       to.dn.RDN().String(),
+			DeleteOldRDN: deleteOldRDN,
+		}
+		if !newParent.Equal(parent) {
+			record.NewSuperior = newParent.String()
+		}
+		return record, true
+	}
+	return nil, false
+}
+
+// modifications returns the mod-specs that change the from attributes into the
+// to attributes. Attributes are either added, deleted or replaced as a whole, or
+// some of their values are deleted and/or added.
+func modifications(from, to []ldif.Attribute) []ldif.Modification {
+	// the first attribute of each attribute description
+	var types []ldif.Attribute
+	for _, a := range append(append([]ldif.Attribute(nil), from...), to...) {
+		if !hasAttribute(types, a.Type) {
+			types = append(types, a)
+		}
+	}
+
+	var modifications []ldif.Modification
+	for _, t := range types {
+		typ := t.Type
+		oldValues, newValues := attributeValues(from, typ), attributeValues(to, typ)
+		if len(newValues) != 0 {
+			typ = newValues[0].Type
+		}
+		// the values of a mod-spec have the type of the modified attribute
+		added, deleted := withType(difference(newValues, oldValues), typ), withType(difference(oldValues, newValues), typ)
+		switch {
+		case len(added) == 0 && len(deleted) == 0:
+		case len(newValues) == 0:
+			modifications = append(modifications, ldif.Modification{Type: ldif.ModDelete, Attribute: typ})
+		case len(oldValues) == 0:
+			modifications = append(modifications, ldif.Modification{Type: ldif.ModAdd, Attribute: typ, Values: added})
+		case len(deleted) == len(oldValues):
+			modifications = append(modifications, ldif.Modification{Type: ldif.ModReplace, Attribute: typ, Values: withType(newValues, typ)})
+		default:
+			if len(deleted) != 0 {
+				modifications = append(modifications, ldif.Modification{Type: ldif.ModDelete, Attribute: typ, Values: deleted})
+			}
+			if len(added) != 0 {
+				modifications = append(modifications, ldif.Modification{Type: ldif.ModAdd, Attribute: typ, Values: added})
+			}
+		}
+	}
+	return modifications
+}
+
+func attributeValues(attributes []ldif.Attribute, typ string) []ldif.Attribute {
+	var values []ldif.Attribute
+	for _, a := range attributes {
+		if hasType(a, typ) {
+			values = append(values, a)
+		}
+	}
+	return values
+}
+
+// difference returns the values of a that are not in b.
+func difference(a, b []ldif.Attribute) []ldif.Attribute {
+	var values []ldif.Attribute
+	for _, v := range a {
+		if indexOf(b, v.Type, v.Value) < 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func withType(values []ldif.Attribute, typ string) []ldif.Attribute {
+	for i := range values {
+		values[i].Type = typ
+	}
+	return values
+}
+
+func newEntries(entries []*ldif.Entry) ([]*entry, error) {
+	parsed := make([]*entry, len(entries))
+	seen := make(map[string]bool)
+	for i, e := range entries {
+		name, err := dn3.ParseDN(e.DN)
+		if err != nil {
+			return nil, err
+		}
+		k := key(*name)
+		if seen[k] {
+			return nil, fmt.Errorf("%s: %w", e.DN, ErrEntryAlreadyExists)
+		}
+		seen[k] = true
+		parsed[i] = &entry{dn: *name, entry: e}
+	}
+	return parsed, nil
+}
+
+func keys(entries []*entry) map[string]*entry {
+	m := make(map[string]*entry, len(entries))
+	for _, e := range entries {
+		m[key(e.dn)] = e
+	}
+	return m
+}
+
+// byDepth returns the entries sorted by depth, parents precede their children.
+func byDepth(entries []*entry) []*entry {
+	sorted := append([]*entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].dn.Depth() < sorted[j].dn.Depth()
+	})
+	return sorted
+}
@@ -0,0 +1,198 @@
+package directory
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/elimity-com/ldif"
+)
+
+func entries(t *testing.T, file string) []*ldif.Entry {
+	records, err := ldif.Parse(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make([]*ldif.Entry, len(records))
+	for i, r := range records {
+		entries[i] = r.(*ldif.Entry)
+	}
+	return entries
+}
+
+// canonical returns a representation of the entries that does not depend on
+// their order, nor on the order of their attributes.
+func canonical(t *testing.T, entries []*ldif.Entry) []string {
+	var lines []string
+	for _, e := range entries {
+		dn, err := ldif.ParseDN(e.DN, ldif.RFC2253)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range e.Attributes {
+			lines = append(lines, dn.Normalize().String()+": "+strings.ToLower(a.Type)+": "+a.Value)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func TestDiff(t *testing.T) {
+	to := `version: 1
+dn: ou=Product Development, dc=airius, dc=com
+objectclass: organizationalUnit
+ou: Product Development
+
+dn: cn=Paula Jensen, ou=Product Development, dc=airius, dc=com
+objectclass: person
+cn: Paula Jensen
+sn: Jensen
+description: Paul
+telephonenumber: +1 408 555 1212
+facsimiletelephonenumber: +1 408 555 9876
+facsimiletelephonenumber: +1 408 555 9877
+
+dn: ou=Accounting, dc=airius, dc=com
+objectclass: organizationalUnit
+ou: Accounting
+
+dn: ou=Product Development Accountants, ou=Accounting, dc=airius, dc=com
+objectclass: organizationalUnit
+ou: PD Accountants
+ou: Product Development Accountants
+
+dn: cn=Horatio Jensen, ou=Product Development Accountants, ou=Accounting, dc=airius, dc=com
+objectclass: person
+cn: Horatio Jensen
+sn: Jensen
+title: Accountant
+
+dn: cn=Fiona Jensen, ou=Marketing, dc=airius, dc=com
+objectclass: person
+cn: Fiona Jensen
+sn: Jensen
+uid: fiona
+
+dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com
+objectclass: person
+cn: Robert Jensen
+sn: Johnson
+`
+	changes, err := Diff(entries(t, content), entries(t, to))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	w := ldif.NewWriter(&buf)
+	w.Width = 0
+	for _, c := range changes {
+		if err := w.Write(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Flush()
+	if expected := `version: 1
+dn: cn=Paul Jensen,ou=Product Development,dc=airius,dc=com
+changetype: modrdn
+newrdn: cn=Paula Jensen
+deleteoldrdn: 1
+
+dn: ou=PD Accountants,ou=Product Development,dc=airius,dc=com
+changetype: modrdn
+newrdn: ou=Product Development Accountants
+deleteoldrdn: 0
+newsuperior: ou=Accounting,dc=airius,dc=com
+
+dn: cn=Fiona Jensen, ou=Marketing, dc=airius, dc=com
+changetype: add
+objectclass: person
+cn: Fiona Jensen
+sn: Jensen
+uid: fiona
+
+dn: cn=Horatio Jensen, ou=Product Development Accountants, ou=Accounting, dc=airius, dc=com
+changetype: modify
+add: title
+title: Accountant
+-
+
+dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com
+changetype: modify
+replace: sn
+sn: Johnson
+-
+
+dn: cn=Ingrid Jensen,ou=Product Support,dc=airius,dc=com
+changetype: delete
+`; buf.String() != expected {
+		t.Errorf("unexpected changes:\n%s", buf.String())
+	}
+
+	d := load(t, content)
+	for _, c := range changes {
+		if err := d.Apply(c); err != nil {
+			t.Fatalf("%s: %v", c.DistinguishedName(), err)
+		}
+	}
+	if got, expected := canonical(t, d.Entries()), canonical(t, entries(t, to)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("applied changes do not result in the to entries:\n%q\n%q", got, expected)
+	}
+}
+
+func TestModifications(t *testing.T) {
+	from := []ldif.Attribute{
+		{Type: "cn", Value: "Barbara Jensen"},
+		{Type: "cn", Value: "Babs Jensen"},
+		{Type: "sn", Value: "Jensen"},
+		{Type: "description", Value: "A big sailing fan."},
+		{Type: "telephonenumber", Value: "+1 408 555 1212"},
+	}
+	to := []ldif.Attribute{
+		{Type: "CN", Value: "Barbara Jensen"},
+		{Type: "CN", Value: "Barbara J Jensen"},
+		{Type: "sn", Value: "Jensen"},
+		{Type: "telephonenumber", Value: "+1 408 555 1234"},
+		{Type: "uid", Value: "bjensen"},
+	}
+	if m, expected := modifications(from, to), []ldif.Modification{
+		{Type: ldif.ModDelete, Attribute: "CN", Values: []ldif.Attribute{{Type: "CN", Value: "Babs Jensen"}}},
+		{Type: ldif.ModAdd, Attribute: "CN", Values: []ldif.Attribute{{Type: "CN", Value: "Barbara J Jensen"}}},
+		{Type: ldif.ModDelete, Attribute: "description"},
+		{Type: ldif.ModReplace, Attribute: "telephonenumber", Values: []ldif.Attribute{{Type: "telephonenumber", Value: "+1 408 555 1234"}}},
+		{Type: ldif.ModAdd, Attribute: "uid", Values: []ldif.Attribute{{Type: "uid", Value: "bjensen"}}},
+	}; !reflect.DeepEqual(m, expected) {
+		t.Errorf("unexpected modifications: %v", m)
+	}
+
+	// the case and the order of the options do not matter
+	from = []ldif.Attribute{
+		{Type: "cn;lang-en", Value: "Babs"},
+		{Type: "description;lang-en;x-a", Value: "A big sailing fan."},
+	}
+	to = []ldif.Attribute{
+		{Type: "cn;Lang-EN", Value: "Babs"},
+		{Type: "description;x-a;lang-en", Value: "A big sailing fan."},
+	}
+	if m := modifications(from, to); len(m) != 0 {
+		t.Errorf("unexpected modifications: %v", m)
+	}
+}
+
+func TestDiffEqual(t *testing.T) {
+	changes, err := Diff(entries(t, content), entries(t, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %d", len(changes))
+	}
+}
+
+func TestDiffDuplicate(t *testing.T) {
+	e := entries(t, "version: 1\ndn: cn=a\ncn: a\n\ndn: CN=A\ncn: a\n")
+	if _, err := Diff(e, nil); err == nil {
+		t.Error("no error for duplicate entries")
+	}
+}
@@ -0,0 +1,329 @@
+// Package directory implements an in-memory directory to which the records of
+// LDIF files can be applied.
+package directory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elimity-com/ldif"
+	"github.com/elimity-com/ldif/dn3"
+)
+
+// The errors that are returned when a change can not be applied, they are named
+// after the corresponding LDAP result codes.
+var (
+	ErrNoSuchObject           = errors.New("no such object")
+	ErrEntryAlreadyExists     = errors.New("entry already exists")
+	ErrNotAllowedOnNonLeaf    = errors.New("not allowed on non-leaf")
+	ErrNoSuchAttribute        = errors.New("no such attribute")
+	ErrAttributeOrValueExists = errors.New("attribute or value exists")
+	ErrUnwillingToPerform     = errors.New("unwilling to perform")
+)
+
+// Directory is an in-memory directory. Entries do not need to have a parent, so
+// it can hold entries of multiple (partial) directory trees. Distinguished names
+// are compared after they are normalized, attribute types are compared
+// case-insensitively and attribute values are compared exactly.
+type Directory struct {
+	entries map[string]*entry
+	// order contains the keys of the entries, in the order they were added.
+	order []string
+}
+
+type entry struct {
+	dn    dn3.DN
+	entry *ldif.Entry
+}
+
+// New returns an empty directory.
+func New() *Directory {
+	return &Directory{
+		entries: make(map[string]*entry),
+	}
+}
+
+// Load adds all entries and applies all change records, in order. It stops at
+// the first record that can not be applied.
+func (d *Directory) Load(records []ldif.Record) error {
+	for _, r := range records {
+		var err error
+		switch r := r.(type) {
+		case *ldif.Entry:
+			err = d.Add(r)
+		case ldif.ChangeRecord:
+			err = d.Apply(r)
+		default:
+			err = fmt.Errorf("unknown record type: %T", r)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.DistinguishedName(), err)
+		}
+	}
+	return nil
+}
+
+// Entry returns a copy of the entry with the given distinguished name.
+func (d *Directory) Entry(dn string) (*ldif.Entry, bool) {
+	name, err := dn3.ParseDN(dn)
+	if err != nil {
+		return nil, false
+	}
+	e, ok := d.entries[key(*name)]
+	if !ok {
+		return nil, false
+	}
+	return copyEntry(e.entry), true
+}
+
+// Entries returns a copy of all entries. Parents precede their children,
+// entries of the same depth are in the order they were added.
+func (d *Directory) Entries() []*ldif.Entry {
+	entries := make([]*entry, len(d.order))
+	for i, k := range d.order {
+		entries[i] = d.entries[k]
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].dn.Depth() < entries[j].dn.Depth()
+	})
+	copies := make([]*ldif.Entry, len(entries))
+	for i, e := range entries {
+		copies[i] = copyEntry(e.entry)
+	}
+	return copies
+}
+
+// Add adds a copy of the entry, it fails if the entry already exists.
+func (d *Directory) Add(e *ldif.Entry) error {
+	name, err := dn3.ParseDN(e.DN)
+	if err != nil {
+		return err
+	}
+	k := key(*name)
+	if _, ok := d.entries[k]; ok {
+		return ErrEntryAlreadyExists
+	}
+	d.entries[k] = &entry{dn: *name, entry: copyEntry(e)}
+	d.order = append(d.order, k)
+	return nil
+}
+
+// Apply applies the change record.
+//   - An entry can not be added if it already exists.
+//   - An entry can only be deleted if it has no children.
+//   - Values can only be added if they do not exist yet, and deleted if they do.
+//   - The values of the new RDN are added to an entry that is renamed, the
+//     values of the old RDN are deleted if DeleteOldRDN is set. The children of
+//     an entry that is moved to a new superior are moved as well.
+func (d *Directory) Apply(r ldif.ChangeRecord) error {
+	name, err := dn3.ParseDN(r.DistinguishedName())
+	if err != nil {
+		return err
+	}
+	k := key(*name)
+
+	if r, ok := r.(*ldif.AddRecord); ok {
+		return d.Add(&ldif.Entry{
+			DN:         r.DN,
+			Attributes: r.Attributes,
+			Comments:   r.Comments,
+		})
+	}
+	e, ok := d.entries[k]
+	if !ok {
+		return ErrNoSuchObject
+	}
+
+	switch r := r.(type) {
+	case *ldif.DeleteRecord:
+		if d.hasChildren(e.dn) {
+			return ErrNotAllowedOnNonLeaf
+		}
+		d.remove(k)
+		return nil
+	case *ldif.ModifyRecord:
+		attributes := e.entry.Attributes
+		for _, m := range r.Modifications {
+			if attributes, err = modify(attributes, m); err != nil {
+				return fmt.Errorf("%s: %w", m.Attribute, err)
+			}
+		}
+		e.entry.Attributes = attributes
+		return nil
+	case *ldif.ModDNRecord:
+		return d.modDN(e, r)
+	default:
+		return fmt.Errorf("unknown record type: %T", r)
+	}
+}
+
+// modify applies a single modification to the attributes and returns the
+// modified attributes.
+func modify(attributes []ldif.Attribute, m ldif.Modification) ([]ldif.Attribute, error) {
+	switch m.Type {
+	case ldif.ModAdd:
+		for _, v := range m.Values {
+			if indexOf(attributes, m.Attribute, v.Value) >= 0 {
+				return nil, ErrAttributeOrValueExists
+			}
+			attributes = append(attributes, ldif.Attribute{Type: m.Attribute, Value: v.Value, URL: v.URL})
+		}
+	case ldif.ModDelete:
+		if !hasAttribute(attributes, m.Attribute) {
+			return nil, ErrNoSuchAttribute
+		}
+		if len(m.Values) == 0 {
+			return withoutAttribute(attributes, m.Attribute), nil
+		}
+		for _, v := range m.Values {
+			i := indexOf(attributes, m.Attribute, v.Value)
+			if i < 0 {
+				return nil, ErrNoSuchAttribute
+			}
+			attributes = append(attributes[:i:i], attributes[i+1:]...)
+		}
+	case ldif.ModReplace:
+		attributes = withoutAttribute(attributes, m.Attribute)
+		for _, v := range m.Values {
+			attributes = append(attributes, ldif.Attribute{Type: m.Attribute, Value: v.Value, URL: v.URL})
+		}
+	default:
+		return nil, fmt.Errorf("unknown modification type: %s", m.Type)
+	}
+	return attributes, nil
+}
+
+// modDN renames the entry and/or moves it, together with its children, to a
+// new superior.
+func (d *Directory) modDN(e *entry, r *ldif.ModDNRecord) error {
+	newRDN, err := dn3.ParseDN(r.NewRDN)
+	if err != nil {
+		return err
+	}
+	if newRDN.Depth() != 1 {
+		return fmt.Errorf("invalid rdn: %s", r.NewRDN)
+	}
+	parent := e.dn.Parent()
+	if r.NewSuperior != "" {
+		superior, err := dn3.ParseDN(r.NewSuperior)
+		if err != nil {
+			return err
+		}
+		if superior.Equal(e.dn) || superior.IsDescendantOf(e.dn) {
+			return fmt.Errorf("can not move an entry below itself: %w", ErrUnwillingToPerform)
+		}
+		parent = *superior
+	}
+	newDN := dn3.DN{RDNs: append([]dn3.RDN{newRDN.RDN()}, parent.RDNs...)}
+	if _, ok := d.entries[key(newDN)]; ok && !newDN.Equal(e.dn) {
+		return ErrEntryAlreadyExists
+	}
+
+	attributes := e.entry.Attributes
+	if r.DeleteOldRDN {
+		for _, a := range e.dn.RDN().Attributes {
+			if i := indexOf(attributes, a.Type, a.Value); i >= 0 {
+				attributes = append(attributes[:i:i], attributes[i+1:]...)
+			}
+		}
+	}
+	for _, a := range newRDN.RDN().Attributes {
+		if indexOf(attributes, a.Type, a.Value) < 0 {
+			attributes = append(attributes, ldif.Attribute{Type: a.Type, Value: a.Value})
+		}
+	}
+	e.entry.Attributes = attributes
+
+	// the children are moved as well, the entry itself is the first one
+	moved := []*entry{e}
+	for _, k := range d.order {
+		if c := d.entries[k]; c.dn.IsDescendantOf(e.dn) {
+			moved = append(moved, c)
+		}
+	}
+	oldDN := e.dn
+	for _, m := range moved {
+		dn, err := m.dn.Rebase(oldDN, newDN)
+		if err != nil {
+			return err
+		}
+		k := key(m.dn)
+		delete(d.entries, k)
+		m.dn, m.entry.DN = dn, dn.String()
+		d.entries[key(dn)] = m
+		for i := range d.order {
+			if d.order[i] == k {
+				d.order[i] = key(dn)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Directory) hasChildren(dn dn3.DN) bool {
+	for _, e := range d.entries {
+		if e.dn.IsDescendantOf(dn) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Directory) remove(k string) {
+	delete(d.entries, k)
+	for i := range d.order {
+		if d.order[i] == k {
+			d.order = append(d.order[:i], d.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// key returns the key of the entry with the given distinguished name.
+func key(dn dn3.DN) string {
+	return dn.Normalize().String()
+}
+
+func copyEntry(e *ldif.Entry) *ldif.Entry {
+	return &ldif.Entry{
+		DN:         e.DN,
+		Attributes: append([]ldif.Attribute(nil), e.Attributes...),
+		Comments:   append([]string(nil), e.Comments...),
+	}
+}
+
+// hasType checks whether the attribute has the given attribute description.
+// Like the values of an entry, descriptions are equal if they have the same
+// type and options, see ldif.AttributeDescription.Equal.
+func hasType(a ldif.Attribute, typ string) bool {
+	return a.Description().Equal(ldif.Attribute{Type: typ}.Description())
+}
+
+func hasAttribute(attributes []ldif.Attribute, typ string) bool {
+	for _, a := range attributes {
+		if hasType(a, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(attributes []ldif.Attribute, typ, value string) int {
+	for i, a := range attributes {
+		if hasType(a, typ) && a.Value == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func withoutAttribute(attributes []ldif.Attribute, typ string) []ldif.Attribute {
+	var without []ldif.Attribute
+	for _, a := range attributes {
+		if !hasType(a, typ) {
+			without = append(without, a)
+		}
+	}
+	return without
+}
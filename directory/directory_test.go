@@ -0,0 +1,149 @@
+package directory
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/elimity-com/ldif"
+)
+
+const content = `version: 1
+dn: ou=Product Development, dc=airius, dc=com
+objectclass: organizationalUnit
+ou: Product Development
+
+dn: cn=Paul Jensen, ou=Product Development, dc=airius, dc=com
+objectclass: person
+cn: Paul Jensen
+sn: Jensen
+description: Paul
+telephonenumber: +1 408 555 1212
+facsimiletelephonenumber: +1 408 555 9876
+facsimiletelephonenumber: +1 408 555 9877
+
+dn: ou=PD Accountants, ou=Product Development, dc=airius, dc=com
+objectclass: organizationalUnit
+ou: PD Accountants
+
+dn: cn=Horatio Jensen, ou=PD Accountants, ou=Product Development, dc=airius, dc=com
+objectclass: person
+cn: Horatio Jensen
+sn: Jensen
+
+dn: ou=Accounting, dc=airius, dc=com
+objectclass: organizationalUnit
+ou: Accounting
+
+dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com
+objectclass: person
+cn: Robert Jensen
+sn: Jensen
+
+dn: cn=Ingrid Jensen, ou=Product Support, dc=airius, dc=com
+objectclass: person
+cn: Ingrid Jensen
+sn: Jensen
+postaladdress: 123 Anystreet
+description: Ingrid
+`
+
+func load(t *testing.T, files ...string) *Directory {
+	d := New()
+	for _, f := range files {
+		records, err := ldif.Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Load(records); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return d
+}
+
+func TestApply(t *testing.T) {
+	changes, _ := ioutil.ReadFile("../testdata/example6.ldif")
+	d := load(t, content, string(changes))
+
+	var dns []string
+	for _, e := range d.Entries() {
+		dns = append(dns, e.DN)
+	}
+	if expected := []string{
+		"ou=Product Development, dc=airius, dc=com",
+		"ou=Accounting, dc=airius, dc=com",
+		"cn=Paula Jensen,ou=Product Development,dc=airius,dc=com",
+		"ou=Product Development Accountants,ou=Accounting,dc=airius,dc=com",
+		"cn=Ingrid Jensen, ou=Product Support, dc=airius, dc=com",
+		"cn=Fiona Jensen, ou=Marketing, dc=airius, dc=com",
+		"cn=Horatio Jensen,ou=Product Development Accountants,ou=Accounting,dc=airius,dc=com",
+	}; !reflect.DeepEqual(dns, expected) {
+		t.Errorf("unexpected entries: %q", dns)
+	}
+
+	paula, ok := d.Entry("CN=Paula Jensen,OU=Product Development,DC=airius,DC=com")
+	if !ok {
+		t.Fatal("entry was not renamed")
+	}
+	if cn := paula.Values("cn"); !reflect.DeepEqual(cn, []string{"Paula Jensen"}) {
+		t.Errorf("unexpected cn values: %q", cn)
+	}
+	if phone := paula.Values("telephonenumber"); !reflect.DeepEqual(phone, []string{"+1 408 555 1234", "+1 408 555 5678"}) {
+		t.Errorf("unexpected telephonenumber values: %q", phone)
+	}
+	if fax := paula.Values("facsimiletelephonenumber"); !reflect.DeepEqual(fax, []string{"+1 408 555 9877"}) {
+		t.Errorf("unexpected facsimiletelephonenumber values: %q", fax)
+	}
+	if description := paula.Values("description"); len(description) != 0 {
+		t.Errorf("description was not deleted: %q", description)
+	}
+
+	accountants, _ := d.Entry("ou=Product Development Accountants, ou=Accounting, dc=airius, dc=com")
+	if ou := accountants.Values("ou"); !reflect.DeepEqual(ou, []string{"PD Accountants", "Product Development Accountants"}) {
+		t.Errorf("unexpected ou values: %q", ou)
+	}
+
+	ingrid, _ := d.Entry("cn=Ingrid Jensen, ou=Product Support, dc=airius, dc=com")
+	if len(ingrid.Attributes) != 3 {
+		t.Errorf("unexpected attributes: %v", ingrid.Attributes)
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	for _, test := range []struct {
+		change string
+		err    error
+	}{
+		{"dn: ou=Accounting, dc=airius, dc=com\nchangetype: add\nou: Accounting\n", ErrEntryAlreadyExists},
+		{"dn: ou=Product Development, dc=airius, dc=com\nchangetype: delete\n", ErrNotAllowedOnNonLeaf},
+		{"dn: ou=Marketing, dc=airius, dc=com\nchangetype: delete\n", ErrNoSuchObject},
+		{"dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com\nchangetype: modify\nadd: sn\nsn: Jensen\n-\n", ErrAttributeOrValueExists},
+		{"dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com\nchangetype: modify\ndelete: description\n-\n", ErrNoSuchAttribute},
+		{"dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com\nchangetype: modify\ndelete: sn\nsn: Jenssen\n-\n", ErrNoSuchAttribute},
+		{"dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com\nchangetype: modrdn\nnewrdn: cn=Paul Jensen\ndeleteoldrdn: 1\nnewsuperior: ou=Product Development, dc=airius, dc=com\n", ErrEntryAlreadyExists},
+		{"dn: ou=Product Development, dc=airius, dc=com\nchangetype: modrdn\nnewrdn: ou=PD\ndeleteoldrdn: 1\nnewsuperior: ou=PD Accountants, ou=Product Development, dc=airius, dc=com\n", ErrUnwillingToPerform},
+	} {
+		d := load(t, content)
+		records, err := ldif.Parse("version: 1\n" + test.change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Load(records); !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, got %v", test.change, test.err, err)
+		}
+	}
+}
+
+func TestApplyAtomic(t *testing.T) {
+	d := load(t, content)
+	records, _ := ldif.Parse("version: 1\ndn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com\nchangetype: modify\nreplace: sn\nsn: Johnson\n-\ndelete: description\n-\n")
+	if err := d.Load(records); err == nil {
+		t.Fatal("no error for an invalid modification")
+	}
+	robert, _ := d.Entry("cn=Robert Jensen, ou=Marketing, dc=airius, dc=com")
+	if sn := robert.Values("sn"); !reflect.DeepEqual(sn, []string{"Jensen"}) {
+		t.Errorf("entry was partially modified: %q", sn)
+	}
+}
@@ -0,0 +1,52 @@
+package ldif
+
+import (
+	"fmt"
+
+	"github.com/elimity-com/ldif/dn"
+	"github.com/elimity-com/ldif/dn3"
+	"github.com/elimity-com/ldif/dn4514"
+)
+
+// DNDialect is a string representation of distinguished names.
+type DNDialect int
+
+const (
+	// RFC2253 is the representation that is referenced by RFC 2849, spaces
+	// around the separators are allowed. (e.g. "cn=Babs, dc=airius, dc=com")
+	RFC2253 DNDialect = iota
+	// RFC4514 obsoletes RFC 2253. It does not allow for spaces around the
+	// separators or quoted values, but does allow unescaped "=" characters and
+	// escaped spaces within values.
+	RFC4514
+	// RFC1779 is the representation of older X.500 tools. RDNs can also be
+	// separated by ";", values can be quoted and OIDs can be prefixed by "OID.".
+	RFC1779
+)
+
+func (d DNDialect) String() string {
+	switch d {
+	case RFC2253:
+		return "RFC 2253"
+	case RFC4514:
+		return "RFC 4514"
+	case RFC1779:
+		return "RFC 1779"
+	default:
+		return fmt.Sprintf("DNDialect(%d)", int(d))
+	}
+}
+
+// ParseDN parses the distinguished name in the given dialect.
+func ParseDN(s string, dialect DNDialect) (*dn3.DN, error) {
+	switch dialect {
+	case RFC2253:
+		return dn3.ParseDN(s)
+	case RFC4514:
+		return dn4514.ParseDN(s)
+	case RFC1779:
+		return dn.ParseDN(s)
+	default:
+		return nil, fmt.Errorf("unknown dn dialect: %v", dialect)
+	}
+}
@@ -42,11 +42,17 @@ func separator(s []rune) Alternatives {
 	)(s)
 }
 
+// RFC 1779 defines it as ( <CR> ) *( " " ), with <CR> being a line break. Line
+// breaks are either CR, LF or CRLF and lines can be empty.
 func optionalSpace(s []rune) Alternatives {
-	return Concat(
+	return Repeat0Inf(
 		`optional-space`,
-		Optional(`[cr]`, cr),
-		Repeat0Inf(`*(" ")`, Rune(` `, ' ')),
+		Alts(
+			`CR / LF / " "`,
+			cr,
+			lf,
+			Rune(` `, ' '),
+		),
 	)(s)
 }
 
@@ -208,6 +214,7 @@ func hexchar(s []rune) Alternatives {
 
 var (
 	cr    = Rune(`CR`, 13)
+	lf    = Rune(`LF`, 10)
 	alpha = Alts(
 		`ALPHA`,
 		Range(`%x41-5A`, 65, 90),  // 65-90
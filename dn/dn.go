@@ -0,0 +1,130 @@
+package dn
+
+import (
+	enchex "encoding/hex"
+	"fmt"
+	"strings"
+
+	. "github.com/elimity-com/abnf/operators"
+	"github.com/elimity-com/ldif/dn3"
+)
+
+// ParseDN parses the string representation of a distinguished name, as defined
+// in RFC 1779. Both "," and ";" separate RDNs, spaces (and line breaks) around
+// the separators are ignored, values can be quoted and OIDs can be prefixed by
+// "OID.". The prefix is removed from the attribute types.
+func ParseDN(s string) (*dn3.DN, error) {
+	input := []rune(s)
+	var d dn3.DN
+	if strings.TrimSpace(s) == "" {
+		return &d, nil
+	}
+	var n *Node
+	for _, a := range name(input) {
+		if len(a.Value) == len(input) {
+			n = a
+			break
+		}
+	}
+	if n == nil {
+		return nil, fmt.Errorf("invalid distinguished name: %q", s)
+	}
+
+	for ; n != nil; n = n.Children[0].GetImmediateSubNode(`name`) {
+		var rdn dn3.RDN
+		for c := n.Children[0].GetImmediateSubNode(`name-component`); c != nil; c = c.Children[0].GetImmediateSubNode(`name-component`) {
+			a, err := newAttributeTypeAndValue(c.Children[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid distinguished name: %q: %v", s, err)
+			}
+			rdn.Attributes = append(rdn.Attributes, a)
+		}
+		d.RDNs = append(d.RDNs, rdn)
+	}
+	return &d, nil
+}
+
+// Convert converts a distinguished name from its RFC 1779 representation to the
+// one of RFC 4514, which is also valid according to RFC 2253.
+// (e.g. `CN=L. Eagle; O="Sue, Grabbit and Runn"; OID.2.5.4.6=GB` becomes
+// `CN=L. Eagle,O=Sue\, Grabbit and Runn,2.5.4.6=GB`)
+func Convert(s string) (string, error) {
+	d, err := ParseDN(s)
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+// newAttributeTypeAndValue converts either an attribute node or the attribute
+// that starts a multi-valued name-component.
+func newAttributeTypeAndValue(n *Node) (dn3.AttributeTypeAndValue, error) {
+	if n.Key != `attribute` {
+		n = n.GetImmediateSubNode(`attribute`)
+	}
+	a := n.Children[0]
+	if a.Key == `string` {
+		return dn3.AttributeTypeAndValue{}, fmt.Errorf("missing attribute type: %q", a.String())
+	}
+
+	typ := strings.TrimSpace(a.Children[0].String())
+	if strings.HasPrefix(typ, "OID.") || strings.HasPrefix(typ, "oid.") {
+		typ = typ[len("OID."):]
+	}
+	if strings.Contains(typ, " ") {
+		return dn3.AttributeTypeAndValue{}, fmt.Errorf("invalid attribute type: %q", typ)
+	}
+	value, err := stringValue(a.Children[4])
+	if err != nil {
+		return dn3.AttributeTypeAndValue{}, err
+	}
+	return dn3.AttributeTypeAndValue{
+		Type:  typ,
+		Value: value,
+		BER:   a.Children[4].Children[0].Key == `"#" <hex>`,
+	}, nil
+}
+
+// stringValue returns the unescaped value of a string node.
+func stringValue(n *Node) (string, error) {
+	value := n.Children[0]
+	switch value.Key {
+	case `"#" <hex>`:
+		raw, err := enchex.DecodeString(value.GetImmediateSubNode(`hex`).String())
+		return string(raw), err
+	case `""" *(stringchar / special / pair) """`:
+		var b strings.Builder
+		for _, c := range value.Children[1].Children {
+			b.WriteString(unescape(c.Children[0]))
+		}
+		return b.String(), nil
+	default:
+		// unescaped leading and trailing spaces are not part of the value
+		var b strings.Builder
+		start, end := -1, 0
+		for _, c := range value.Children {
+			c = c.Children[0]
+			if c.Key != `pair` && strings.TrimSpace(c.String()) == "" {
+				b.WriteString(c.String())
+				continue
+			}
+			if start < 0 {
+				start = b.Len()
+			}
+			b.WriteString(unescape(c))
+			end = b.Len()
+		}
+		if start < 0 {
+			return "", nil
+		}
+		return b.String()[start:end], nil
+	}
+}
+
+// unescape returns the character of a stringchar, special or pair node.
+func unescape(n *Node) string {
+	if n.Key == `pair` {
+		return string(n.Value[1:])
+	}
+	return n.String()
+}
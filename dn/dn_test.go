@@ -0,0 +1,46 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	for _, test := range []struct {
+		str, converted string
+	}{
+		{``, ``},
+		{`CN=Marshall T. Rose, O=Dover Beach Consulting, L=Santa Clara, ST=California, C=US`, `CN=Marshall T. Rose,O=Dover Beach Consulting,L=Santa Clara,ST=California,C=US`},
+		{`CN=Christian Huitema; O=INRIA; C=FR`, `CN=Christian Huitema,O=INRIA,C=FR`},
+		{"CN=Steve Kille ,\n\nO =   ISODE Consortium,\nC=GB", `CN=Steve Kille,O=ISODE Consortium,C=GB`},
+		{"CN=Steve Kille,\r\nO=ISODE Consortium,\r\nC=GB", `CN=Steve Kille,O=ISODE Consortium,C=GB`},
+		{`OU=Sales + CN=J. Smith, O=Widget Inc., C=US`, `OU=Sales+CN=J. Smith,O=Widget Inc.,C=US`},
+		{`CN=L. Eagle, O="Sue, Grabbit and Runn", C=GB`, `CN=L. Eagle,O=Sue\, Grabbit and Runn,C=GB`},
+		{`CN=L. Eagle, O=Sue\, Grabbit and Runn, C=GB`, `CN=L. Eagle,O=Sue\, Grabbit and Runn,C=GB`},
+		{`CN=" Quoted \"Spaces\" "`, `CN=\ Quoted \"Spaces\"\ `},
+		{`OID.2.5.4.3=Steve Kille, oid.2.5.4.6=GB`, `2.5.4.3=Steve Kille,2.5.4.6=GB`},
+		{`CN=#04024869`, `CN=#04024869`},
+		{`CN="a=b<c>;#"`, `CN=a\=b\<c\>\;\#`},
+	} {
+		converted, err := Convert(test.str)
+		if err != nil {
+			t.Errorf("could not convert %q: %v", test.str, err)
+			continue
+		}
+		if converted != test.converted {
+			t.Errorf("%q: got %q, expected %q", test.str, converted, test.converted)
+		}
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	for _, str := range []string{
+		`Steve Kille`,
+		`Common Name=Steve Kille`,
+		`CN=#123`,
+		`CN="unterminated`,
+	} {
+		if _, err := Convert(str); err == nil {
+			t.Errorf("no error for %q", str)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package dn
+package dn3
 
 // RFC 2253: 3. Parsing a String back to a Distinguished Name
 
@@ -23,7 +23,9 @@ func name(s []rune) Alternatives {
 			`*("," name-component)`,
 			Concat(
 				`"," name-component`,
+				spaces,
 				Rune(`,`, ','),
+				spaces,
 				nameComponent,
 			),
 		),
@@ -38,7 +40,9 @@ func nameComponent(s []rune) Alternatives {
 			`*("+" attributeTypeAndValue)`,
 			Concat(
 				`"+" attributeTypeAndValue`,
+				spaces,
 				Rune(`+`, '+'),
+				spaces,
 				attributeTypeAndValue,
 			),
 		),
@@ -49,19 +53,23 @@ func attributeTypeAndValue(s []rune) Alternatives {
 	return Concat(
 		`attributeTypeAndValue`,
 		attributeType,
+		spaces,
 		Rune(`=`, '='),
+		spaces,
 		attributeValue,
 	)(s)
 }
 
+// RFC 2253 defines it as (ALPHA 1*keychar) / oid, which does not allow for
+// attribute types of a single character. (e.g. "C")
 func attributeType(s []rune) Alternatives {
 	return Alts(
 		`attributeType`,
 		Concat(
-			`ALPHA 1*keychar`,
+			`ALPHA *keychar`,
 			alpha,
-			Repeat1Inf(
-				`1*keychar`,
+			Repeat0Inf(
+				`*keychar`,
 				keychar,
 			),
 		),
@@ -69,6 +77,15 @@ func attributeType(s []rune) Alternatives {
 	)(s)
 }
 
+// RFC 2253: 4. Relationship with RFC 1779 and LDAPv2
+// Implementations MUST allow for space (' ' ASCII 32) characters to be present
+// between name-component and ',', between attributeTypeAndValue and '+',
+// between attributeType and '=', and between '=' and attributeValue. These
+// space characters are ignored when parsing.
+func spaces(s []rune) Alternatives {
+	return Repeat0Inf(`*(" ")`, Rune(` `, ' '))(s)
+}
+
 func keychar(s []rune) Alternatives {
 	return Alts(
 		`keyChar`,
@@ -145,16 +162,19 @@ func special(s []rune) Alternatives {
 	)(s)
 }
 
+// RFC 2253 escapes leading and trailing spaces with a "\" (2.4), but does not
+// include them in its definition of pair.
 func pair(s []rune) Alternatives {
 	return Concat(
 		`pair`,
 		Rune(`\`, 92), // "\"
 		Alts(
-			`special / "\" / QUOTATION / hexpair`,
+			`special / "\" / QUOTATION / hexpair / " "`,
 			special,
 			Rune(`\`, 92),
 			quotation,
 			hexpair,
+			Rune(` `, ' '),
 		),
 	)(s)
 }
@@ -1,4 +1,4 @@
-package dn
+package dn3
 
 import (
 	"testing"
@@ -33,7 +33,7 @@ func TestExamples(t *testing.T) {
 		// BER encoding of an OCTET STRING containing two bytes
 		`1.3.6.1.4.1.1466.0=#04024869,O=Test,C=GB`,
 		// 5 letters: L, U, C WITH CARON, I, C WITH ACUTE
-		`SN=Lu` + string(0xC48D) + `i` + string(0xC487),
+		`SN=Lu` + "\u010d" + `i` + "\u0107",
 	} {
 		if name([]rune(str)) == nil {
 			t.Errorf("could not parse string: %s", str)
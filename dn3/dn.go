@@ -0,0 +1,206 @@
+package dn3
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+// DN is a distinguished name, a sequence of relative distinguished names. The
+// first RDN is the one of the entry itself, the last one is the one that is the
+// closest to the root of the directory. The DN of the root has no RDNs.
+type DN struct {
+	RDNs []RDN
+}
+
+// RDN is a relative distinguished name, a set of one or more attribute type and
+// value pairs. Multi-valued RDNs are separated by "+", as in
+// "OU=Sales+CN=J. Smith".
+type RDN struct {
+	Attributes []AttributeTypeAndValue
+}
+
+// AttributeTypeAndValue is a single attribute type and value pair of an RDN.
+type AttributeTypeAndValue struct {
+	// Type is either a short name (e.g. "CN") or a dotted-decimal OID.
+	Type string
+	// Value is the unescaped value. If the value was given in its "#" hexstring
+	// form, it is the BER encoding of the value.
+	Value string
+	// BER is true if the value is the BER encoding of the value, it is written
+	// in its "#" hexstring form.
+	BER bool
+}
+
+// String returns the string representation of the distinguished name, as
+// defined in RFC 4514. (e.g. "CN=Steve Kille,O=Isode Limited,C=GB")
+func (d DN) String() string {
+	rdns := make([]string, len(d.RDNs))
+	for i, rdn := range d.RDNs {
+		rdns[i] = rdn.String()
+	}
+	return strings.Join(rdns, ",")
+}
+
+// String returns the string representation of the relative distinguished name,
+// multiple values are separated by "+".
+func (r RDN) String() string {
+	attributes := make([]string, len(r.Attributes))
+	for i, a := range r.Attributes {
+		attributes[i] = a.String()
+	}
+	return strings.Join(attributes, "+")
+}
+
+// String returns the type and the escaped value, separated by "=". BER encoded
+// values are written in their "#" hexstring form.
+//
+// Other binary values, that are either not valid UTF-8 or contain control
+// characters, are not written as a hexstring but escaped as hexpairs (e.g.
+// "\00"). RFC 4514 defines a hexstring as the BER encoding of the value, so
+// writing their raw bytes as a hexstring would change the value when it is
+// parsed again.
+func (a AttributeTypeAndValue) String() string {
+	if a.BER {
+		return a.Type + "=#" + hex.EncodeToString([]byte(a.Value))
+	}
+	return a.Type + "=" + EscapeValue(a.Value)
+}
+
+// isBinary checks whether the value is either not valid UTF-8 or contains
+// control characters.
+func isBinary(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeValue escapes the special characters (including a leading "#"), "\",
+// QUOTATION and leading or trailing spaces with a "\", so the value can be used
+// in the string representation of a distinguished name. Control characters
+// and bytes that are not valid UTF-8 are escaped as hexpairs. (e.g. "\00")
+func EscapeValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == utf8.RuneError && size == 1 || unicode.IsControl(r):
+			for _, c := range []byte(value[i : i+size]) {
+				fmt.Fprintf(&b, "\\%02X", c)
+			}
+			i += size
+			continue
+		case strings.ContainsRune(`,=+<>#;\"`, r):
+			b.WriteRune('\\')
+		case r == ' ' && (i == 0 || i == len(value)-1):
+			b.WriteRune('\\')
+		}
+		b.WriteString(value[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+// ParseDN parses the string representation of a distinguished name, as defined
+// in RFC 2253. Spaces around the separators are ignored, as required by section
+// 4 of the RFC, so the distinguished names of LDIF files can be parsed as well.
+func ParseDN(s string) (*DN, error) {
+	input := []rune(s)
+	var dn *Node
+	for _, a := range distinguishedName(input) {
+		if len(a.Value) == len(input) {
+			dn = a
+			break
+		}
+	}
+	if dn == nil {
+		return nil, fmt.Errorf("invalid distinguished name: %q", s)
+	}
+
+	var d DN
+	for _, c := range dn.GetSubNodes(`name-component`) {
+		var rdn RDN
+		for _, a := range c.GetSubNodes(`attributeTypeAndValue`) {
+			str := a.GetImmediateSubNode(`string`)
+			value, err := attributeValueString(str)
+			if err != nil {
+				return nil, fmt.Errorf("invalid distinguished name: %q: %v", s, err)
+			}
+			rdn.Attributes = append(rdn.Attributes, AttributeTypeAndValue{
+				Type:  a.GetImmediateSubNode(`attributeType`).String(),
+				Value: value,
+				BER:   str.Children[0].Key == `"#" hexstring`,
+			})
+		}
+		d.RDNs = append(d.RDNs, rdn)
+	}
+	return &d, nil
+}
+
+// attributeValueString returns the unescaped value of a string node.
+func attributeValueString(n *Node) (string, error) {
+	value := n.Children[0]
+	switch value.Key {
+	case `"#" hexstring`:
+		raw, err := hex.DecodeString(value.GetImmediateSubNode(`hexstring`).String())
+		return string(raw), err
+	case `QUOTATION *(quotechar / pair) QUOTATION`:
+		raw, _, _ := unescape(value.Children[1].Children)
+		return string(raw), nil
+	default:
+		// unescaped leading and trailing spaces are not part of the value
+		raw, start, end := unescape(value.Children)
+		if end < start {
+			return "", nil
+		}
+		return string(raw[start:end]), nil
+	}
+}
+
+// unescape returns the bytes of the given stringchar/quotechar or pair nodes,
+// and the bounds of the bytes without the leading and trailing unescaped
+// spaces.
+func unescape(nodes Children) ([]byte, int, int) {
+	var raw []byte
+	start, end := -1, 0
+	for _, n := range nodes {
+		c := n.Children[0]
+		if c.Key != `pair` {
+			if c.Value[0] == ' ' {
+				raw = append(raw, ' ')
+				continue
+			}
+			if start < 0 {
+				start = len(raw)
+			}
+			raw = append(raw, string(c.Value)...)
+			end = len(raw)
+			continue
+		}
+		if start < 0 {
+			start = len(raw)
+		}
+		escaped := c.Children[1].Children[0]
+		if escaped.Key == `hexpair` {
+			b, _ := hex.DecodeString(escaped.String())
+			raw = append(raw, b...)
+		} else {
+			raw = append(raw, string(escaped.Value)...)
+		}
+		end = len(raw)
+	}
+	if start < 0 {
+		start = len(raw)
+	}
+	return raw, start, end
+}
@@ -0,0 +1,135 @@
+package dn3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDN(t *testing.T) {
+	for _, test := range []struct {
+		str  string
+		rdns []RDN
+	}{
+		{``, nil},
+		{`CN=Steve Kille,O=Isode Limited,C=GB`, []RDN{
+			{[]AttributeTypeAndValue{{"CN", "Steve Kille", false}}},
+			{[]AttributeTypeAndValue{{"O", "Isode Limited", false}}},
+			{[]AttributeTypeAndValue{{"C", "GB", false}}},
+		}},
+		{`OU=Sales+CN=J. Smith,O=Widget Inc.,C=US`, []RDN{
+			{[]AttributeTypeAndValue{{"OU", "Sales", false}, {"CN", "J. Smith", false}}},
+			{[]AttributeTypeAndValue{{"O", "Widget Inc.", false}}},
+			{[]AttributeTypeAndValue{{"C", "US", false}}},
+		}},
+		{`CN=L. Eagle,O=Sue\, Grabbit and Runn,C=GB`, []RDN{
+			{[]AttributeTypeAndValue{{"CN", "L. Eagle", false}}},
+			{[]AttributeTypeAndValue{{"O", "Sue, Grabbit and Runn", false}}},
+			{[]AttributeTypeAndValue{{"C", "GB", false}}},
+		}},
+		{`1.3.6.1.4.1.1466.0=#04024869,O=Test,C=GB`, []RDN{
+			{[]AttributeTypeAndValue{{"1.3.6.1.4.1.1466.0", "\x04\x02Hi", true}}},
+			{[]AttributeTypeAndValue{{"O", "Test", false}}},
+			{[]AttributeTypeAndValue{{"C", "GB", false}}},
+		}},
+		{`SN=Lu\C4\8Di\C4\87`, []RDN{
+			{[]AttributeTypeAndValue{{"SN", "Lučić", false}}},
+		}},
+		{`CN="Sue, Grabbit \"and\" Runn "`, []RDN{
+			{[]AttributeTypeAndValue{{"CN", `Sue, Grabbit "and" Runn `, false}}},
+		}},
+		{`cn=Barbara Jensen, ou=Product Development , dc = airius,dc=com`, []RDN{
+			{[]AttributeTypeAndValue{{"cn", "Barbara Jensen", false}}},
+			{[]AttributeTypeAndValue{{"ou", "Product Development", false}}},
+			{[]AttributeTypeAndValue{{"dc", "airius", false}}},
+			{[]AttributeTypeAndValue{{"dc", "com", false}}},
+		}},
+		{`cn=\ Space\ \ `, []RDN{
+			{[]AttributeTypeAndValue{{"cn", " Space  ", false}}},
+		}},
+	} {
+		dn, err := ParseDN(test.str)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", test.str, err)
+			continue
+		}
+		if !reflect.DeepEqual(dn.RDNs, test.rdns) {
+			t.Errorf("%q: got %v, expected %v", test.str, dn.RDNs, test.rdns)
+		}
+	}
+}
+
+func TestParseDNInvalid(t *testing.T) {
+	for _, str := range []string{
+		`CN`,
+		`CN=Steve,`,
+		`=Steve`,
+		`CN=Sue, Grabbit`,
+		`CN=#0`,
+		`CN=a\`,
+		`CN=a\zz`,
+	} {
+		if _, err := ParseDN(str); err == nil {
+			t.Errorf("no error for %q", str)
+		}
+	}
+}
+
+func TestDNString(t *testing.T) {
+	for _, test := range []struct {
+		dn  DN
+		str string
+	}{
+		{DN{}, ``},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"OU", "Sales", false}, {"CN", "J. Smith", false}}},
+			{[]AttributeTypeAndValue{{"O", "Widget Inc.", false}}},
+		}}, `OU=Sales+CN=J. Smith,O=Widget Inc.`},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"O", `Sue, Grabbit "and" Runn`, false}}},
+		}}, `O=Sue\, Grabbit \"and\" Runn`},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"CN", `#1 = a+b; <c> \ `, false}}},
+		}}, `CN=\#1 \= a\+b\; \<c\> \\\ `},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"CN", " Space ", false}}},
+		}}, `CN=\ Space\ `},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"1.3.6.1.4.1.1466.0", "\x04\x02Hi", true}}},
+			{[]AttributeTypeAndValue{{"CN", "\xff", false}}},
+		}}, `1.3.6.1.4.1.1466.0=#04024869,CN=\FF`},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"CN", "Before\rAfter", false}}},
+			{[]AttributeTypeAndValue{{"CN", "a\x00b\u0085", false}}},
+		}}, `CN=Before\0DAfter,CN=a\00b\C2\85`},
+		{DN{[]RDN{
+			{[]AttributeTypeAndValue{{"SN", "Lučić", false}}},
+		}}, `SN=Lučić`},
+	} {
+		if str := test.dn.String(); str != test.str {
+			t.Errorf("got %q, expected %q", str, test.str)
+		}
+		dn, err := ParseDN(test.str)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", test.str, err)
+			continue
+		}
+		if !reflect.DeepEqual(*dn, test.dn) {
+			t.Errorf("%q: got %v, expected %v", test.str, *dn, test.dn)
+		}
+	}
+}
+
+func TestEscapeValue(t *testing.T) {
+	for value, escaped := range map[string]string{
+		"Barbara Jensen": `Barbara Jensen`,
+		"Sue, Grabbit":   `Sue\, Grabbit`,
+		"#1 ":            `\#1\ `,
+		` "a\b" `:        `\ \"a\\b\"\ `,
+		"a\x00b\x7f":     `a\00b\7F`,
+		"\xc4":           `\C4`,
+	} {
+		if e := EscapeValue(value); e != escaped {
+			t.Errorf("%q: got %q, expected %q", value, e, escaped)
+		}
+	}
+}
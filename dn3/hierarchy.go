@@ -0,0 +1,82 @@
+package dn3
+
+import (
+	"fmt"
+)
+
+// Depth returns the number of RDNs of the distinguished name, the root has a
+// depth of zero.
+func (d DN) Depth() int {
+	return len(d.RDNs)
+}
+
+// RDN returns the relative distinguished name of the entry itself, the first
+// RDN. It returns an empty RDN for the root.
+func (d DN) RDN() RDN {
+	if len(d.RDNs) == 0 {
+		return RDN{}
+	}
+	return d.RDNs[0]
+}
+
+// Parent returns the distinguished name of the parent of the entry. The parent
+// of the root is the root itself.
+func (d DN) Parent() DN {
+	if len(d.RDNs) == 0 {
+		return DN{}
+	}
+	return DN{RDNs: d.RDNs[1:]}
+}
+
+// IsDescendantOf checks whether the entry is located below the given base, it
+// is not a descendant of itself. RDNs are compared after they are normalized.
+func (d DN) IsDescendantOf(base DN) bool {
+	return len(base.RDNs) < len(d.RDNs) && d.hasSuffix(base)
+}
+
+// Rebase replaces the base of the distinguished name by a new base, as in moving
+// the entry, or the entry of one of its ancestors, to a new parent. The
+// distinguished name needs to be either equal to or a descendant of the old
+// base.
+func (d DN) Rebase(oldBase, newBase DN) (DN, error) {
+	if len(d.RDNs) < len(oldBase.RDNs) || !d.hasSuffix(oldBase) {
+		return DN{}, fmt.Errorf("%s is not located below %s", d, oldBase)
+	}
+	n := len(d.RDNs) - len(oldBase.RDNs)
+	rdns := make([]RDN, 0, n+len(newBase.RDNs))
+	rdns = append(rdns, d.RDNs[:n]...)
+	return DN{RDNs: append(rdns, newBase.RDNs...)}, nil
+}
+
+// CommonAncestor returns the deepest distinguished name that both are equal to
+// or descendants of. It is the root if they have nothing in common.
+func (d DN) CommonAncestor(other DN) DN {
+	i, j := len(d.RDNs)-1, len(other.RDNs)-1
+	for ; 0 <= i && 0 <= j; i, j = i-1, j-1 {
+		if !d.RDNs[i].Equal(other.RDNs[j]) {
+			break
+		}
+	}
+	return DN{RDNs: d.RDNs[i+1:]}
+}
+
+// Equal checks whether both relative distinguished names are equal, after they
+// are normalized.
+func (r RDN) Equal(other RDN) bool {
+	return DN{RDNs: []RDN{r}}.Equal(DN{RDNs: []RDN{other}})
+}
+
+// hasSuffix checks whether the last RDNs of the distinguished name are equal to
+// the RDNs of the given base.
+func (d DN) hasSuffix(base DN) bool {
+	n := len(d.RDNs) - len(base.RDNs)
+	if n < 0 {
+		return false
+	}
+	for i, rdn := range base.RDNs {
+		if !d.RDNs[n+i].Equal(rdn) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package dn3
+
+import (
+	"testing"
+)
+
+func mustParseDN(t *testing.T, s string) DN {
+	dn, err := ParseDN(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *dn
+}
+
+func TestHierarchy(t *testing.T) {
+	dn := mustParseDN(t, `cn=Paula Jensen, ou=Product Development, dc=airius, dc=com`)
+	if depth := dn.Depth(); depth != 4 {
+		t.Errorf("expected a depth of 4, got %d", depth)
+	}
+	if rdn := dn.RDN().String(); rdn != "cn=Paula Jensen" {
+		t.Errorf("unexpected rdn: %s", rdn)
+	}
+	if parent := dn.Parent().String(); parent != "ou=Product Development,dc=airius,dc=com" {
+		t.Errorf("unexpected parent: %s", parent)
+	}
+
+	root := DN{}
+	if root.Depth() != 0 || root.Parent().Depth() != 0 || len(root.RDN().Attributes) != 0 {
+		t.Errorf("unexpected root: %v", root)
+	}
+}
+
+func TestIsDescendantOf(t *testing.T) {
+	dn := mustParseDN(t, `cn=Paula Jensen, ou=Product Development, dc=airius, dc=com`)
+	for _, test := range []struct {
+		base       string
+		descendant bool
+	}{
+		{``, true},
+		{`DC=AIRIUS,DC=COM`, true},
+		{`ou=Product Development, dc=airius, dc=com`, true},
+		{`cn=Paula Jensen, ou=Product Development, dc=airius, dc=com`, false},
+		{`ou=Accounting, dc=airius, dc=com`, false},
+		{`dc=com, dc=airius`, false},
+	} {
+		if dn.IsDescendantOf(mustParseDN(t, test.base)) != test.descendant {
+			t.Errorf("%q: expected descendant to be %v", test.base, test.descendant)
+		}
+	}
+}
+
+func TestRebase(t *testing.T) {
+	oldBase := mustParseDN(t, `ou=PD Accountants, ou=Product Development, dc=airius, dc=com`)
+	newBase := mustParseDN(t, `ou=Product Development Accountants, ou=Accounting, dc=airius, dc=com`)
+	for _, test := range []struct {
+		dn, rebased string
+	}{
+		{`ou=PD Accountants, ou=Product Development, dc=airius, dc=com`, `ou=Product Development Accountants,ou=Accounting,dc=airius,dc=com`},
+		{`cn=Paula Jensen,ou=PD Accountants,ou=Product Development,dc=airius,dc=com`, `cn=Paula Jensen,ou=Product Development Accountants,ou=Accounting,dc=airius,dc=com`},
+	} {
+		dn, err := mustParseDN(t, test.dn).Rebase(oldBase, newBase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str := dn.String(); str != test.rebased {
+			t.Errorf("got %q, expected %q", str, test.rebased)
+		}
+	}
+
+	if _, err := mustParseDN(t, `ou=Product Development, dc=airius, dc=com`).Rebase(oldBase, newBase); err == nil {
+		t.Error("no error for a dn outside of the old base")
+	}
+}
+
+func TestCommonAncestor(t *testing.T) {
+	for _, test := range []struct {
+		a, b, ancestor string
+	}{
+		{`cn=Paula Jensen, ou=Product Development, dc=airius, dc=com`, `cn=Bjorn Jensen, ou=Accounting, dc=airius, dc=com`, `dc=airius,dc=com`},
+		{`cn=Paula Jensen, ou=Product Development, dc=airius, dc=com`, `OU=Product Development, DC=Airius, DC=com`, `ou=Product Development,dc=airius,dc=com`},
+		{`dc=airius, dc=com`, `dc=airius, dc=org`, ``},
+	} {
+		ancestor := mustParseDN(t, test.a).CommonAncestor(mustParseDN(t, test.b))
+		if str := ancestor.String(); str != test.ancestor {
+			t.Errorf("got %q, expected %q", str, test.ancestor)
+		}
+	}
+}
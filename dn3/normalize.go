@@ -0,0 +1,87 @@
+package dn3
+
+import (
+	"sort"
+	"strings"
+)
+
+// shortNames maps the OIDs of the well-known attribute types to their short
+// names, in lower case.
+var shortNames = map[string]string{
+	"2.5.4.3":                    "cn",
+	"2.5.4.4":                    "sn",
+	"2.5.4.5":                    "serialnumber",
+	"2.5.4.6":                    "c",
+	"2.5.4.7":                    "l",
+	"2.5.4.8":                    "st",
+	"2.5.4.9":                    "street",
+	"2.5.4.10":                   "o",
+	"2.5.4.11":                   "ou",
+	"2.5.4.12":                   "title",
+	"2.5.4.42":                   "givenname",
+	"0.9.2342.19200300.100.1.1":  "uid",
+	"0.9.2342.19200300.100.1.25": "dc",
+	"1.2.840.113549.1.9.1":       "email",
+}
+
+// caseIgnore contains the attribute types, by their short names, of which the
+// values are matched case-insensitively.
+var caseIgnore = map[string]bool{
+	"cn":           true,
+	"sn":           true,
+	"serialnumber": true,
+	"c":            true,
+	"l":            true,
+	"st":           true,
+	"street":       true,
+	"o":            true,
+	"ou":           true,
+	"title":        true,
+	"givenname":    true,
+	"uid":          true,
+	"dc":           true,
+	"email":        true,
+}
+
+// Normalize returns the normalized form of the distinguished name. Attribute
+// types are lower-cased and OIDs, with or without the "OID." prefix of RFC 1779,
+// are replaced by the short names of well-known attribute types. The values of
+// well-known attribute types are lower-cased and their insignificant spaces are
+// removed. The values of a multi-valued RDN are sorted.
+func (d DN) Normalize() DN {
+	normalized := DN{RDNs: make([]RDN, len(d.RDNs))}
+	for i, rdn := range d.RDNs {
+		attributes := make([]AttributeTypeAndValue, len(rdn.Attributes))
+		for j, a := range rdn.Attributes {
+			attributes[j] = a.normalize()
+		}
+		sort.Slice(attributes, func(i, j int) bool {
+			if attributes[i].Type != attributes[j].Type {
+				return attributes[i].Type < attributes[j].Type
+			}
+			return attributes[i].Value < attributes[j].Value
+		})
+		normalized.RDNs[i] = RDN{Attributes: attributes}
+	}
+	return normalized
+}
+
+// Equal checks whether both distinguished names are equal, after they are
+// normalized.
+func (d DN) Equal(other DN) bool {
+	return d.Normalize().String() == other.Normalize().String()
+}
+
+func (a AttributeTypeAndValue) normalize() AttributeTypeAndValue {
+	typ := strings.ToLower(a.Type)
+	typ = strings.TrimPrefix(typ, "oid.")
+	if name, ok := shortNames[typ]; ok {
+		typ = name
+	}
+	value := a.Value
+	if caseIgnore[typ] && !a.BER && !isBinary(value) {
+		// insignificant spaces are leading, trailing or repeated spaces
+		value = strings.ToLower(strings.Join(strings.Fields(value), " "))
+	}
+	return AttributeTypeAndValue{Type: typ, Value: value, BER: a.BER}
+}
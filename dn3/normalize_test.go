@@ -0,0 +1,57 @@
+package dn3
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, test := range []struct {
+		str, normalized string
+	}{
+		{`CN=Fiona Jensen,OU=Marketing,DC=airius,DC=com`, `cn=fiona jensen,ou=marketing,dc=airius,dc=com`},
+		{`2.5.4.3=Steve  Kille,0.9.2342.19200300.100.1.25=COM`, `cn=steve kille,dc=com`},
+		{`OU=Sales+CN=J. Smith,O=Widget Inc.`, `cn=j. smith+ou=sales,o=widget inc.`},
+		{`CN=\ Space\ `, `cn=space`},
+		{`description=Case Sensitive`, `description=Case Sensitive`},
+		{`1.3.6.1.4.1.1466.0=#04024869,CN=#04024869`, `1.3.6.1.4.1.1466.0=#04024869,cn=#04024869`},
+	} {
+		dn, err := ParseDN(test.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str := dn.Normalize().String(); str != test.normalized {
+			t.Errorf("%q: got %q, expected %q", test.str, str, test.normalized)
+		}
+	}
+
+	dn := DN{[]RDN{{[]AttributeTypeAndValue{{"OID.2.5.4.3", "Steve", false}}}}}
+	if str := dn.Normalize().String(); str != "cn=steve" {
+		t.Errorf("got %q, expected %q", str, "cn=steve")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, test := range []struct {
+		a, b  string
+		equal bool
+	}{
+		{`cn=Fiona Jensen, ou=Marketing, dc=airius, dc=com`, `CN=Fiona Jensen,OU=Marketing,DC=airius,DC=com`, true},
+		{`OU=Sales+CN=J. Smith`, `cn=j. smith+ou=SALES`, true},
+		{`2.5.4.11=Sales`, `ou=sales`, true},
+		{`cn=Fiona Jensen`, `cn=Fiona Jensen,dc=com`, false},
+		{`cn=Fiona Jensen`, `cn=Fiona  Jensen`, true},
+		{`description=Fiona`, `description=fiona`, false},
+	} {
+		a, err := ParseDN(test.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseDN(test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Equal(*b) != test.equal {
+			t.Errorf("%q and %q: expected equal to be %v", test.a, test.b, test.equal)
+		}
+	}
+}
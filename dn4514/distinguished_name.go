@@ -0,0 +1,239 @@
+package dn4514
+
+// RFC 4514: 3. Parsing a String Back to a Distinguished Name
+
+import (
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+func distinguishedName(s []rune) Alternatives {
+	return Optional(
+		`distinguishedName`,
+		Concat(
+			`relativeDistinguishedName *( COMMA relativeDistinguishedName )`,
+			relativeDistinguishedName,
+			Repeat0Inf(
+				`*( COMMA relativeDistinguishedName )`,
+				Concat(
+					`COMMA relativeDistinguishedName`,
+					Rune(`COMMA`, ','),
+					relativeDistinguishedName,
+				),
+			),
+		),
+	)(s)
+}
+
+func relativeDistinguishedName(s []rune) Alternatives {
+	return Concat(
+		`relativeDistinguishedName`,
+		attributeTypeAndValue,
+		Repeat0Inf(
+			`*( PLUS attributeTypeAndValue )`,
+			Concat(
+				`PLUS attributeTypeAndValue`,
+				Rune(`PLUS`, '+'),
+				attributeTypeAndValue,
+			),
+		),
+	)(s)
+}
+
+func attributeTypeAndValue(s []rune) Alternatives {
+	return Concat(
+		`attributeTypeAndValue`,
+		attributeType,
+		Rune(`EQUALS`, '='),
+		attributeValue,
+	)(s)
+}
+
+func attributeType(s []rune) Alternatives {
+	return Alts(
+		`attributeType`,
+		descr,
+		numericoid,
+	)(s)
+}
+
+func attributeValue(s []rune) Alternatives {
+	return Alts(
+		`attributeValue`,
+		str,
+		hexstring,
+	)(s)
+}
+
+// string
+func str(s []rune) Alternatives {
+	return Optional(
+		`string`,
+		Concat(
+			`( leadchar / pair ) [ *( stringchar / pair ) ( trailchar / pair ) ]`,
+			Alts(`leadchar / pair`, leadchar, pair),
+			Optional(
+				`[ *( stringchar / pair ) ( trailchar / pair ) ]`,
+				Concat(
+					`*( stringchar / pair ) ( trailchar / pair )`,
+					Repeat0Inf(`*( stringchar / pair )`, Alts(
+						`stringchar / pair`,
+						stringchar,
+						pair,
+					)),
+					Alts(`trailchar / pair`, trailchar, pair),
+				),
+			),
+		),
+	)(s)
+}
+
+func leadchar(s []rune) Alternatives {
+	return Alts(
+		`leadchar`,
+		Range(`%x01-1F`, 0x01, 0x1F),
+		Rune(`%x21`, 0x21),
+		Range(`%x24-2A`, 0x24, 0x2A),
+		Range(`%x2D-3A`, 0x2D, 0x3A),
+		Rune(`%x3D`, 0x3D),
+		Range(`%x3F-5B`, 0x3F, 0x5B),
+		Range(`%x5D-7F`, 0x5D, 0x7F),
+		utfmb,
+	)(s)
+}
+
+func trailchar(s []rune) Alternatives {
+	return Alts(
+		`trailchar`,
+		Range(`%x01-1F`, 0x01, 0x1F),
+		Rune(`%x21`, 0x21),
+		Range(`%x23-2A`, 0x23, 0x2A),
+		Range(`%x2D-3A`, 0x2D, 0x3A),
+		Rune(`%x3D`, 0x3D),
+		Range(`%x3F-5B`, 0x3F, 0x5B),
+		Range(`%x5D-7F`, 0x5D, 0x7F),
+		utfmb,
+	)(s)
+}
+
+func stringchar(s []rune) Alternatives {
+	return Alts(
+		`stringchar`,
+		Range(`%x01-21`, 0x01, 0x21),
+		Range(`%x23-2A`, 0x23, 0x2A),
+		Range(`%x2D-3A`, 0x2D, 0x3A),
+		Rune(`%x3D`, 0x3D),
+		Range(`%x3F-5B`, 0x3F, 0x5B),
+		Range(`%x5D-7F`, 0x5D, 0x7F),
+		utfmb,
+	)(s)
+}
+
+func pair(s []rune) Alternatives {
+	return Concat(
+		`pair`,
+		Rune(`ESC`, '\\'),
+		Alts(
+			`ESC / special / hexpair`,
+			Rune(`ESC`, '\\'),
+			special,
+			hexpair,
+		),
+	)(s)
+}
+
+func special(s []rune) Alternatives {
+	return Alts(
+		`special`,
+		escaped,
+		Rune(`SPACE`, ' '),
+		Rune(`SHARP`, '#'),
+		Rune(`EQUALS`, '='),
+	)(s)
+}
+
+func escaped(s []rune) Alternatives {
+	return Alts(
+		`escaped`,
+		Rune(`DQUOTE`, '"'),
+		Rune(`PLUS`, '+'),
+		Rune(`COMMA`, ','),
+		Rune(`SEMI`, ';'),
+		Rune(`LANGLE`, '<'),
+		Rune(`RANGLE`, '>'),
+	)(s)
+}
+
+func hexstring(s []rune) Alternatives {
+	return Concat(
+		`hexstring`,
+		Rune(`SHARP`, '#'),
+		Repeat1Inf(`1*hexpair`, hexpair),
+	)(s)
+}
+
+func hexpair(s []rune) Alternatives {
+	return Concat(`hexpair`, hexchar, hexchar)(s)
+}
+
+// RFC 4512: 1.4. Common ABNF Productions
+
+func descr(s []rune) Alternatives {
+	return Concat(
+		`descr`,
+		alpha,
+		Repeat0Inf(`*keychar`, keychar),
+	)(s)
+}
+
+func keychar(s []rune) Alternatives {
+	return Alts(
+		`keychar`,
+		alpha,
+		digit,
+		Rune(`HYPHEN`, '-'),
+	)(s)
+}
+
+func numericoid(s []rune) Alternatives {
+	return Concat(
+		`numericoid`,
+		number,
+		Repeat1Inf(`1*( DOT number )`, Concat(
+			`DOT number`,
+			Rune(`DOT`, '.'),
+			number,
+		)),
+	)(s)
+}
+
+func number(s []rune) Alternatives {
+	return Alts(
+		`number`,
+		digit,
+		Concat(
+			`LDIGIT 1*DIGIT`,
+			Range(`LDIGIT`, '1', '9'),
+			Repeat1Inf(`1*DIGIT`, digit),
+		),
+	)(s)
+}
+
+func hexchar(s []rune) Alternatives {
+	return Alts(`HEX`, digit,
+		Range(`%x41-46`, 'A', 'F'),
+		Range(`%x61-66`, 'a', 'f'),
+	)(s)
+}
+
+var (
+	alpha = Alts(
+		`ALPHA`,
+		Range(`%x41-5A`, 65, 90),  // 65-90
+		Range(`%x61-7A`, 97, 122), // 97-122
+	)
+	digit = Range(`DIGIT`, 48, 57) // 48-57
+	// UTF-8 characters that are encoded in more than one byte
+	utfmb = Range(`UTFMB`, 0x80, utf8.MaxRune)
+)
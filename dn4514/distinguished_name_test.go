@@ -0,0 +1,49 @@
+package dn4514
+
+import (
+	"testing"
+)
+
+func TestLeadChar(t *testing.T) {
+	for _, v := range []string{"\x00", ` `, `"`, `#`, `+`, `,`, `;`, `<`, `>`, `\`} {
+		if leadchar([]rune(v)) != nil {
+			t.Errorf("value found for %q", v)
+		}
+	}
+}
+
+func TestTrailChar(t *testing.T) {
+	for _, v := range []string{"\x00", ` `, `"`, `+`, `,`, `;`, `<`, `>`, `\`} {
+		if trailchar([]rune(v)) != nil {
+			t.Errorf("value found for %q", v)
+		}
+	}
+}
+
+func TestStringChar(t *testing.T) {
+	for _, v := range []string{"\x00", `"`, `+`, `,`, `;`, `<`, `>`, `\`} {
+		if stringchar([]rune(v)) != nil {
+			t.Errorf("value found for %q", v)
+		}
+	}
+}
+
+func TestExamples(t *testing.T) {
+	for _, str := range []string{
+		`UID=jsmith,DC=example,DC=net`,
+		`OU=Sales+CN=J.  Smith,DC=example,DC=net`,
+		`CN=James \"Jim\" Smith\, III,DC=example,DC=net`,
+		`CN=Before\0dAfter,DC=example,DC=net`,
+		`1.3.6.1.4.1.1466.0=#04024869`,
+		`CN=Lu\C4\8Di\C4\87`,
+	} {
+		input := []rune(str)
+		found := false
+		for _, a := range distinguishedName(input) {
+			found = found || len(a.Value) == len(input)
+		}
+		if !found {
+			t.Errorf("could not parse string: %s", str)
+		}
+	}
+}
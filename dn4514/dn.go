@@ -0,0 +1,76 @@
+package dn4514
+
+import (
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+	"github.com/elimity-com/ldif/dn3"
+)
+
+// ParseDN parses the string representation of a distinguished name, as defined
+// in RFC 4514. Contrary to RFC 2253, spaces around the separators and quoted
+// values are not allowed, and the unescaped values have to be valid UTF-8.
+func ParseDN(s string) (*dn3.DN, error) {
+	input := []rune(s)
+	var dn *Node
+	for _, a := range distinguishedName(input) {
+		if len(a.Value) == len(input) {
+			dn = a
+			break
+		}
+	}
+	if dn == nil {
+		return nil, fmt.Errorf("invalid distinguished name: %q", s)
+	}
+
+	var d dn3.DN
+	for _, c := range dn.GetSubNodes(`relativeDistinguishedName`) {
+		var rdn dn3.RDN
+		for _, a := range c.GetSubNodes(`attributeTypeAndValue`) {
+			v := a.GetImmediateSubNode(`attributeValue`)
+			value, err := attributeValueString(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid distinguished name: %q: %v", s, err)
+			}
+			rdn.Attributes = append(rdn.Attributes, dn3.AttributeTypeAndValue{
+				Type:  a.GetImmediateSubNode(`attributeType`).String(),
+				Value: value,
+				BER:   v.Children[0].Key == `hexstring`,
+			})
+		}
+		d.RDNs = append(d.RDNs, rdn)
+	}
+	return &d, nil
+}
+
+// attributeValueString returns the unescaped value of an attributeValue node.
+func attributeValueString(n *Node) (string, error) {
+	value := n.Children[0]
+	if value.Key == `hexstring` {
+		raw, err := hex.DecodeString(value.String()[1:])
+		return string(raw), err
+	}
+
+	var raw []byte
+	for v := value.Value; len(v) != 0; v = v[1:] {
+		if v[0] != '\\' {
+			raw = append(raw, string(v[0])...)
+			continue
+		}
+		// the grammar guarantees that an ESC is followed by either a single
+		// character or a hexpair
+		if 3 <= len(v) {
+			if b, err := hex.DecodeString(string(v[1:3])); err == nil {
+				raw, v = append(raw, b...), v[2:]
+				continue
+			}
+		}
+		raw, v = append(raw, string(v[1])...), v[1:]
+	}
+	if !utf8.Valid(raw) {
+		return "", fmt.Errorf("value is not valid UTF-8: %q", value.String())
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,66 @@
+package dn4514
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elimity-com/ldif/dn3"
+)
+
+func TestParseDN(t *testing.T) {
+	for _, test := range []struct {
+		str  string
+		rdns []dn3.RDN
+	}{
+		{``, nil},
+		{`OU=Sales+CN=J.  Smith,DC=example,DC=net`, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "OU", Value: "Sales"}, {Type: "CN", Value: "J.  Smith"}}},
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "DC", Value: "example"}}},
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "DC", Value: "net"}}},
+		}},
+		{`CN=James \"Jim\" Smith\, III`, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "CN", Value: `James "Jim" Smith, III`}}},
+		}},
+		{`CN=Before\0dAfter`, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "CN", Value: "Before\rAfter"}}},
+		}},
+		{`1.3.6.1.4.1.1466.0=#04024869`, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "1.3.6.1.4.1.1466.0", Value: "\x04\x02Hi", BER: true}}},
+		}},
+		{`CN=Lu\C4\8Di\C4\87`, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "CN", Value: "Lučić"}}},
+		}},
+		{`CN=\ a=b\\\5C\ `, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "CN", Value: ` a=b\\ `}}},
+		}},
+		{`CN=`, []dn3.RDN{
+			{Attributes: []dn3.AttributeTypeAndValue{{Type: "CN", Value: ""}}},
+		}},
+	} {
+		dn, err := ParseDN(test.str)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", test.str, err)
+			continue
+		}
+		if !reflect.DeepEqual(dn.RDNs, test.rdns) {
+			t.Errorf("%q: got %v, expected %v", test.str, dn.RDNs, test.rdns)
+		}
+	}
+}
+
+func TestParseDNInvalid(t *testing.T) {
+	for _, str := range []string{
+		`CN`,
+		`CN=a, DC=net`,
+		`CN="quoted"`,
+		`CN=#a`,
+		`CN= a`,
+		`CN=a `,
+		`1=a`,
+		`CN=\FF`,
+	} {
+		if _, err := ParseDN(str); err == nil {
+			t.Errorf("no error for %q", str)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ldif
+
+import (
+	"testing"
+)
+
+// TestParseDNDialects shows where the dialects differ, an empty value means
+// that the distinguished name is invalid in that dialect.
+func TestParseDNDialects(t *testing.T) {
+	for _, test := range []struct {
+		str                       string
+		rfc2253, rfc4514, rfc1779 string
+	}{
+		{`CN=Steve Kille,O=Isode Limited,C=GB`, `CN=Steve Kille`, `CN=Steve Kille`, `CN=Steve Kille`},
+		// spaces around separators
+		{`cn=Babs, dc=airius`, `cn=Babs`, ``, `cn=Babs`},
+		{`cn = Babs`, `cn=Babs`, ``, `cn=Babs`},
+		// semicolon separators
+		{`cn=Babs; dc=airius`, ``, ``, `cn=Babs`},
+		// quoted values
+		{`CN="Sue, Grabbit"`, `CN=Sue\, Grabbit`, ``, `CN=Sue\, Grabbit`},
+		// unescaped "="
+		{`CN=a=b`, ``, `CN=a\=b`, ``},
+		// escaped spaces
+		{`CN=\ a\ `, `CN=\ a\ `, `CN=\ a\ `, ``},
+		{`CN=a\ b`, `CN=a b`, `CN=a b`, ``},
+		// hexpairs that are not valid UTF-8
+		{`CN=\FF`, `CN=\FF`, ``, ``},
+		{`CN=Lu\C4\8Di\C4\87`, `CN=Lučić`, `CN=Lučić`, ``},
+		// OIDs need at least two components in RFC 4514
+		{`1=a`, `1=a`, ``, `1=a`},
+		{`OID.2.5.4.3=a`, ``, ``, `2.5.4.3=a`},
+		{`CN=#04024869`, `CN=#04024869`, `CN=#04024869`, `CN=#04024869`},
+	} {
+		for _, d := range []struct {
+			dialect DNDialect
+			rdn     string
+		}{
+			{RFC2253, test.rfc2253},
+			{RFC4514, test.rfc4514},
+			{RFC1779, test.rfc1779},
+		} {
+			dn, err := ParseDN(test.str, d.dialect)
+			if d.rdn == "" {
+				if err == nil {
+					t.Errorf("%v: no error for %q", d.dialect, test.str)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%v: %v", d.dialect, err)
+				continue
+			}
+			if rdn := dn.RDN().String(); rdn != d.rdn {
+				t.Errorf("%v: %q: got %q, expected %q", d.dialect, test.str, rdn, d.rdn)
+			}
+		}
+	}
+
+	if _, err := ParseDN(`cn=Babs`, DNDialect(-1)); err == nil {
+		t.Error("no error for an unknown dialect")
+	}
+}
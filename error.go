@@ -0,0 +1,304 @@
+package ldif
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+// ParseError describes where and why the input is not valid LDIF.
+type ParseError struct {
+	// Line and Column are the position of the error in the input, both start
+	// at 1. The column is counted in characters.
+	Line, Column int
+	// DN is the distinguished name of the record that contains the error, it is
+	// empty if it is not known.
+	DN string
+	// Rule is the name of the grammar rule that was expected at the position
+	// of the error. (e.g. "dn-spec" or "mod-spec")
+	Rule string
+	// Snippet is (a part of) the offending line.
+	Snippet string
+	// Err is the reason why the input matches the rule, but is still invalid.
+	// It is nil if the input does not match the rule.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("line %d, column %d: expected %s", e.Line, e.Column, e.Rule)
+	if e.Err != nil {
+		msg = fmt.Sprintf("line %d, column %d: invalid %s", e.Line, e.Column, e.Rule)
+	}
+	if e.DN != "" {
+		msg += fmt.Sprintf(" (dn: %s)", e.DN)
+	}
+	if e.Snippet != "" {
+		msg += fmt.Sprintf(": %q", e.Snippet)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// line is a logical line of the input. Folded lines are unfolded, so a line can
+// span multiple lines of the input.
+type line struct {
+	// text is the unfolded line, including its separator.
+	text string
+	// number is the number of the (first) line in the input, starting at 1.
+	number int
+	// folds are the offsets in text, in runes, at which the continuation lines
+	// start.
+	folds []int
+}
+
+// position returns the line and column in the input of the given offset, in
+// runes, within the unfolded line.
+func (l line) position(offset int) (int, int) {
+	number, start := l.number, 0
+	for _, fold := range l.folds {
+		if offset < fold {
+			break
+		}
+		// the continuation line starts with a space
+		number, start = number+1, fold-1
+	}
+	return number, offset - start + 1
+}
+
+// newParseError returns a ParseError at the given offset, in runes, within the
+// line.
+func (l line) newParseError(offset int, dn, rule string, err error) *ParseError {
+	number, column := l.position(offset)
+	return &ParseError{
+		Line:    number,
+		Column:  column,
+		DN:      dn,
+		Rule:    rule,
+		Snippet: snippet([]rune(strings.TrimRight(l.text, "\r\n")), offset),
+		Err:     err,
+	}
+}
+
+// snippet returns at most 80 characters of the text around the given offset.
+func snippet(text []rune, offset int) string {
+	const width = 80
+	if len(text) <= width {
+		return string(text)
+	}
+	start := offset - width/2
+	if start < 0 {
+		start = 0
+	}
+	if len(text) < start+width {
+		start = len(text) - width
+	}
+	s := string(text[start : start+width])
+	if start != 0 {
+		s = "..." + s
+	}
+	if start+width != len(text) {
+		s += "..."
+	}
+	return s
+}
+
+// diagnosis looks for the first error in the lines of a single record.
+type diagnosis struct {
+	lines []line
+	dn    string
+}
+
+// diagnose returns the first error in the given lines of a record, comment
+// lines are ignored. It returns nil if no error was found.
+func diagnose(lines []line) *ParseError {
+	d := diagnosis{}
+	for _, l := range lines {
+		if !strings.HasPrefix(l.text, "#") {
+			d.lines = append(d.lines, l)
+		}
+	}
+	if len(d.lines) == 0 {
+		return nil
+	}
+
+	nodes, err := d.match(0, `dn-spec`, dnSpec, sep)
+	if err != nil {
+		return err
+	}
+	d.dn, _ = distinguishedNameValue(nodes[0])
+
+	if len(d.lines) == 1 {
+		return d.end(`attrval-spec`)
+	}
+	if next := d.lines[1].text; !strings.HasPrefix(next, "control:") && !strings.HasPrefix(next, "changetype:") {
+		return d.matchAll(1, len(d.lines), `attrval-spec`, attrvalSpecParts)
+	}
+
+	i := 1
+	for ; i < len(d.lines) && strings.HasPrefix(d.lines[i].text, "control:"); i++ {
+		if _, err := d.match(i, `control`, controlParts...); err != nil {
+			return err
+		}
+	}
+	if i == len(d.lines) {
+		return d.end(`changerecord`)
+	}
+	// the changetype line is followed by the first line of the change, or
+	// ends the record, so the change itself only needs to match partially
+	text := []rune(d.lines[i].text)
+	var change []*Node
+	for _, parts := range [][]Operator{changeAddParts, changeDeleteParts, changeModifyParts, changeModdnParts} {
+		if nodes := longestMatch(text, append(changetypeParts, parts...)); length(change) < length(nodes) {
+			change = nodes
+		}
+	}
+	if offset := length(change); offset != len(text) {
+		return d.lines[i].newParseError(offset, d.dn, `changerecord`, nil)
+	}
+	i++
+
+	switch change[2].String() {
+	case "add":
+		if i == len(d.lines) {
+			return d.end(`attrval-spec`)
+		}
+		return d.matchAll(i, len(d.lines), `attrval-spec`, attrvalSpecParts)
+	case "delete":
+		if i != len(d.lines) {
+			return d.lines[i].newParseError(0, d.dn, `SEP`, nil)
+		}
+	case "modify":
+		for i < len(d.lines) {
+			if _, err := d.match(i, `mod-spec`, modSpecParts...); err != nil {
+				return err
+			}
+			j := i + 1
+			for j < len(d.lines) && strings.TrimRight(d.lines[j].text, "\r\n") != "-" {
+				j++
+			}
+			if err := d.matchAll(i+1, j, `attrval-spec`, attrvalSpecParts); err != nil {
+				return err
+			}
+			if j == len(d.lines) {
+				return d.end(`"-"`)
+			}
+			i = j + 1
+		}
+	default:
+		for _, l := range []struct {
+			rule     string
+			parts    []Operator
+			optional bool
+		}{
+			{`newrdn`, newRDNParts, false},
+			{`deleteoldrdn`, deleteOldRDNParts, false},
+			{`newsuperior`, newSuperiorParts, true},
+		} {
+			if i == len(d.lines) {
+				if l.optional {
+					return nil
+				}
+				return d.end(l.rule)
+			}
+			if _, err := d.match(i, l.rule, l.parts...); err != nil {
+				return err
+			}
+			i++
+		}
+		if i != len(d.lines) {
+			return d.lines[i].newParseError(0, d.dn, `SEP`, nil)
+		}
+	}
+	return nil
+}
+
+// match matches the parts of a rule against the whole line at index i. It
+// returns the matched nodes, or an error at the position where the line stops
+// matching. Base64 encoded values are decoded to check whether they are valid.
+func (d *diagnosis) match(i int, rule string, parts ...Operator) ([]*Node, *ParseError) {
+	l := d.lines[i]
+	text := []rune(l.text)
+	nodes := longestMatch(text, parts)
+	if offset := length(nodes); offset != len(text) {
+		return nil, l.newParseError(offset, d.dn, rule, nil)
+	}
+	if err := d.decode(l, nodes, 0); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// matchAll matches the parts of a rule against the lines from index i up to j.
+func (d *diagnosis) matchAll(i, j int, rule string, parts []Operator) *ParseError {
+	for ; i < j; i++ {
+		if _, err := d.match(i, rule, parts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decode decodes the base64 encoded values within the nodes, of which the first
+// one starts at the given offset within the line. It returns an error at the
+// first value that is invalid.
+func (d *diagnosis) decode(l line, nodes []*Node, offset int) *ParseError {
+	for _, n := range nodes {
+		var err error
+		switch n.Key {
+		case `BASE64-STRING`:
+			_, err = decodeBase64(n)
+		case `base64-distinguishedName`, `base64-rdn`:
+			_, err = decodeBase64UTF8(n)
+		default:
+			if err := d.decode(l, n.Children, offset); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return l.newParseError(offset, d.dn, n.Key, err)
+		}
+		offset += len(n.Value)
+	}
+	return nil
+}
+
+// end returns an error at the end of the last line.
+func (d *diagnosis) end(rule string) *ParseError {
+	l := d.lines[len(d.lines)-1]
+	return l.newParseError(len([]rune(strings.TrimRight(l.text, "\r\n"))), d.dn, rule, nil)
+}
+
+// longestMatch matches the parts one after the other, trying every alternative
+// of each part, and returns the nodes of the match that gets the furthest into
+// the text. The nodes end at the first part that does not match, so their
+// length is the position at which the text stops matching.
+func longestMatch(text []rune, parts []Operator) []*Node {
+	if len(parts) == 0 {
+		return nil
+	}
+	var best []*Node
+	for _, n := range parts[0](text) {
+		nodes := append([]*Node{n}, longestMatch(text[len(n.Value):], parts[1:])...)
+		if best == nil || length(best) < length(nodes) {
+			best = nodes
+		}
+	}
+	return best
+}
+
+// length returns the number of characters matched by the consecutive nodes.
+func length(nodes []*Node) int {
+	var n int
+	for _, node := range nodes {
+		n += len(node.Value)
+	}
+	return n
+}
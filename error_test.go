@@ -0,0 +1,60 @@
+package ldif
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	for _, test := range []struct {
+		input        string
+		line, column int
+		dn, rule     string
+	}{
+		{"version: 1\ndn cn=a\ncn: a\n", 2, 1, "", `dn-spec`},
+		{"version: 1\ndn: cn=a\ncn: a\n\ndn: cn=b\ncn: b\ncn:: YQ=\n", 7, 6, "cn=b", `BASE64-STRING`},
+		{"version: 1\ndn: cn=a\nc_n: a\n", 3, 2, "cn=a", `attrval-spec`},
+		{"version: 1\ndn: cn=a\nmember;range=0-*: cn=b\nc_n: a\n", 4, 2, "cn=a", `attrval-spec`},
+		{"version: 1\ndn: cn=a\ncontrol: 1.2.3 yes\nchangetype: delete\n", 3, 15, "cn=a", `control`},
+		{"version: 1\ndn: cn=a\nchangetype: remove\n", 3, 13, "cn=a", `changerecord`},
+		{"version: 1\ndn: cn=a\nchangetype: add\n", 3, 16, "cn=a", `attrval-spec`},
+		{"version: 1\ndn: cn=a\nchangetype: delete\ncn: a\n", 4, 1, "cn=a", `SEP`},
+		{"version: 1\ndn: cn=a\nchangetype: modify\nadd cn\ncn: a\n-\n", 4, 1, "cn=a", `mod-spec`},
+		{"version: 1\ndn: cn=a\nchangetype: modify\nadd: cn\ncn: a\n", 5, 6, "cn=a", `"-"`},
+		{"version: 1\ndn: cn=a\nchangetype: modrdn\nnewrdn: cn=b\ndeleteoldrdn: true\n", 5, 15, "cn=a", `deleteoldrdn`},
+		{"version: 1\ndn: cn=a\nchangetype: modrdn\nnewrdn: cn=b\n", 4, 13, "cn=a", `deleteoldrdn`},
+		// base64 values
+		{"version: 1\ndn: cn=a\ncn:: YQ\n", 3, 6, "cn=a", `BASE64-STRING`},
+		{"version: 1\ndn: cn=a\ncn:: YR==\n", 3, 6, "cn=a", `BASE64-STRING`},
+		{"version: 1\ndn:: /w==\ncn: a\n", 2, 6, "", `base64-distinguishedName`},
+		{"version: 1\ndn: cn=a\nchangetype: modrdn\nnewrdn:: Y249/w==\ndeleteoldrdn: 1\n", 4, 10, "cn=a", `base64-rdn`},
+		{"version: 1\ndn: cn=a\nchangetype: modrdn\nnewrdn: cn=b\ndeleteoldrdn: 1\nnewsuperior:: 3w==\n", 6, 15, "cn=a", `base64-distinguishedName`},
+		// folded lines
+		{"version: 1\ndn: cn=a,\n dc=com\ndescription: abc\n de\x00f\n", 5, 4, "cn=a,dc=com", `attrval-spec`},
+	} {
+		_, err := Parse(test.input)
+		e, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("expected a parse error for %q, got %v", test.input, err)
+			continue
+		}
+		if e.Line != test.line || e.Column != test.column || e.DN != test.dn || e.Rule != test.rule {
+			t.Errorf("unexpected parse error for %q: %v", test.input, err)
+		}
+	}
+}
+
+func TestParseErrorSnippet(t *testing.T) {
+	value := strings.Repeat("a", 100) + "\x00" + strings.Repeat("b", 100)
+	_, err := Parse("version: 1\ndn: cn=a\ndescription: " + value + "\n")
+	e, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+	if e.Column != 114 {
+		t.Errorf("unexpected column: %d", e.Column)
+	}
+	if e.Snippet != "..."+strings.Repeat("a", 40)+"\x00"+strings.Repeat("b", 39)+"..." {
+		t.Errorf("unexpected snippet: %q", e.Snippet)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/elimity-com/ldif
 
 go 1.14
 
-require github.com/elimity-com/abnf v0.0.0-20200604095209-4af5a0cb72bc
+require (
+	github.com/elimity-com/abnf v0.0.0-20200604095209-4af5a0cb72bc
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,309 @@
+package ldif
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// jsonRecord is the JSON representation of a record. Entries have no
+// changetype, the other fields are only used by the change records they belong
+// to.
+type jsonRecord struct {
+	DN            string             `json:"dn" yaml:"dn"`
+	ChangeType    string             `json:"changetype,omitempty" yaml:"changetype,omitempty"`
+	Controls      []jsonControl      `json:"controls,omitempty" yaml:"controls,omitempty"`
+	Attributes    []jsonValue        `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	Modifications []jsonModification `json:"modifications,omitempty" yaml:"modifications,omitempty"`
+	NewRDN        string             `json:"newrdn,omitempty" yaml:"newrdn,omitempty"`
+	DeleteOldRDN  bool               `json:"deleteoldrdn,omitempty" yaml:"deleteoldrdn,omitempty"`
+	NewSuperior   string             `json:"newsuperior,omitempty" yaml:"newsuperior,omitempty"`
+	Comments      []string           `json:"comments,omitempty" yaml:"comments,omitempty"`
+}
+
+// jsonValue is the JSON representation of an attribute value. Exactly one of
+// Value, Base64 and URL is set. Binary values, and values of attributes with the
+// "binary" option, are base64 encoded.
+type jsonValue struct {
+	Type   string  `json:"type,omitempty" yaml:"type,omitempty"`
+	Value  *string `json:"value,omitempty" yaml:"value,omitempty"`
+	Base64 *string `json:"base64,omitempty" yaml:"base64,omitempty"`
+	URL    *string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+type jsonControl struct {
+	OID         string  `json:"oid" yaml:"oid"`
+	Criticality bool    `json:"criticality,omitempty" yaml:"criticality,omitempty"`
+	Value       *string `json:"value,omitempty" yaml:"value,omitempty"`
+	Base64      *string `json:"base64,omitempty" yaml:"base64,omitempty"`
+	URL         *string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+type jsonModification struct {
+	Op        ModType     `json:"op" yaml:"op"`
+	Attribute string      `json:"attribute" yaml:"attribute"`
+	Values    []jsonValue `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// MarshalJSON returns the JSON representation of the records, an array of
+// record objects. The schema is documented in the README, in short:
+//
+//	{
+//	  "dn": "cn=Barbara Jensen, dc=airius, dc=com",
+//	  "changetype": "add" | "delete" | "modify" | "modrdn", (omitted for entries)
+//	  "controls": [{"oid": "1.2.840.113556.1.4.805", "criticality": true, "value" | "base64" | "url": "..."}],
+//	  "attributes": [{"type": "cn;lang-en", "value" | "base64" | "url": "..."}],
+//	  "modifications": [{"op": "add" | "delete" | "replace", "attribute": "cn", "values": [{"value": "..."}]}],
+//	  "newrdn": "cn=Babs", "deleteoldrdn": true, "newsuperior": "dc=com",
+//	  "comments": ["..."]
+//	}
+func MarshalJSON(records []Record) ([]byte, error) {
+	rs, err := newJSONRecords(records)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(rs, "", "  ")
+}
+
+// UnmarshalJSON parses the JSON representation of records, as returned by
+// MarshalJSON.
+func UnmarshalJSON(data []byte) ([]Record, error) {
+	var rs []jsonRecord
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, err
+	}
+	return jsonRecords(rs)
+}
+
+func newJSONRecords(records []Record) ([]jsonRecord, error) {
+	rs := make([]jsonRecord, 0, len(records))
+	for _, r := range records {
+		jr, err := newJSONRecord(r)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, jr)
+	}
+	return rs, nil
+}
+
+func jsonRecords(rs []jsonRecord) ([]Record, error) {
+	records := make([]Record, 0, len(rs))
+	for i, jr := range rs {
+		r, err := jr.record()
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %v", i, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func newJSONRecord(r Record) (jsonRecord, error) {
+	if e, ok := r.(*Entry); ok {
+		return jsonRecord{
+			DN:         e.DN,
+			Attributes: newJSONValues(e.Attributes, ""),
+			Comments:   e.Comments,
+		}, nil
+	}
+	c, ok := r.(ChangeRecord)
+	if !ok {
+		return jsonRecord{}, fmt.Errorf("unknown record type: %T", r)
+	}
+	header := c.Header()
+	jr := jsonRecord{
+		DN:       header.DN,
+		Comments: header.Comments,
+	}
+	for _, control := range header.Controls {
+		jc := jsonControl{OID: control.OID, Criticality: control.Criticality}
+		if control.URL {
+			url := control.Value
+			jc.URL = &url
+		} else if control.Value != "" {
+			jc.Value, jc.Base64 = encodeJSONValue(control.Value)
+		}
+		jr.Controls = append(jr.Controls, jc)
+	}
+
+	switch r := r.(type) {
+	case *AddRecord:
+		jr.ChangeType = "add"
+		jr.Attributes = newJSONValues(r.Attributes, "")
+	case *DeleteRecord:
+		jr.ChangeType = "delete"
+	case *ModifyRecord:
+		jr.ChangeType = "modify"
+		for _, m := range r.Modifications {
+			jr.Modifications = append(jr.Modifications, jsonModification{
+				Op:        m.Type,
+				Attribute: m.Attribute,
+				Values:    newJSONValues(m.Values, m.Attribute),
+			})
+		}
+	case *ModDNRecord:
+		jr.ChangeType = "modrdn"
+		jr.NewRDN = r.NewRDN
+		jr.DeleteOldRDN = r.DeleteOldRDN
+		jr.NewSuperior = r.NewSuperior
+	default:
+		return jsonRecord{}, fmt.Errorf("unknown record type: %T", r)
+	}
+	return jr, nil
+}
+
+// newJSONValues converts the attributes, the type is omitted if it is equal to
+// the given type.
+func newJSONValues(attributes []Attribute, typ string) []jsonValue {
+	values := make([]jsonValue, 0, len(attributes))
+	for _, a := range attributes {
+		v := jsonValue{}
+		if a.Type != typ {
+			v.Type = a.Type
+		}
+		if a.URL {
+			url := a.Value
+			v.URL = &url
+		} else if a.Description().Binary() {
+			encoded := base64.StdEncoding.EncodeToString([]byte(a.Value))
+			v.Base64 = &encoded
+		} else {
+			v.Value, v.Base64 = encodeJSONValue(a.Value)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// encodeJSONValue returns either the value itself, or its base64 encoding if it
+// is not valid UTF-8 or contains control characters other than TAB, LF and CR.
+func encodeJSONValue(value string) (*string, *string) {
+	if utf8.ValidString(value) && strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r'
+	}) < 0 {
+		return &value, nil
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(value))
+	return nil, &encoded
+}
+
+func (jr jsonRecord) record() (Record, error) {
+	if jr.ChangeType == "" {
+		attributes, err := jsonAttributes(jr.Attributes, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(attributes) == 0 {
+			return nil, fmt.Errorf("missing attributes")
+		}
+		return &Entry{DN: jr.DN, Attributes: attributes, Comments: jr.Comments}, nil
+	}
+
+	change := Change{DN: jr.DN, Comments: jr.Comments}
+	for _, jc := range jr.Controls {
+		control := Control{OID: jc.OID, Criticality: jc.Criticality}
+		if jc.URL != nil {
+			if jc.Value != nil || jc.Base64 != nil {
+				return nil, fmt.Errorf("control %s: expected either a value or an url", jc.OID)
+			}
+			control.Value, control.URL = *jc.URL, true
+		} else if jc.Value != nil || jc.Base64 != nil {
+			value, err := jsonValue{Value: jc.Value, Base64: jc.Base64}.value()
+			if err != nil {
+				return nil, err
+			}
+			control.Value = value
+		}
+		change.Controls = append(change.Controls, control)
+	}
+
+	switch jr.ChangeType {
+	case "add":
+		attributes, err := jsonAttributes(jr.Attributes, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(attributes) == 0 {
+			return nil, fmt.Errorf("missing attributes")
+		}
+		return &AddRecord{Change: change, Attributes: attributes}, nil
+	case "delete":
+		return &DeleteRecord{Change: change}, nil
+	case "modify":
+		record := ModifyRecord{Change: change}
+		for _, jm := range jr.Modifications {
+			switch jm.Op {
+			case ModAdd, ModDelete, ModReplace:
+			default:
+				return nil, fmt.Errorf("unknown modification op: %q", jm.Op)
+			}
+			values, err := jsonAttributes(jm.Values, jm.Attribute)
+			if err != nil {
+				return nil, err
+			}
+			record.Modifications = append(record.Modifications, Modification{
+				Type:      jm.Op,
+				Attribute: jm.Attribute,
+				Values:    values,
+			})
+		}
+		return &record, nil
+	case "modrdn", "moddn":
+		if jr.NewRDN == "" {
+			return nil, fmt.Errorf("missing newrdn")
+		}
+		return &ModDNRecord{
+			Change:       change,
+			NewRDN:       jr.NewRDN,
+			DeleteOldRDN: jr.DeleteOldRDN,
+			NewSuperior:  jr.NewSuperior,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown changetype: %q", jr.ChangeType)
+	}
+}
+
+// jsonAttributes converts the values to attributes, values without a type get
+// the given type.
+func jsonAttributes(values []jsonValue, typ string) ([]Attribute, error) {
+	attributes := make([]Attribute, 0, len(values))
+	for _, v := range values {
+		a := Attribute{Type: v.Type}
+		if a.Type == "" {
+			a.Type = typ
+		}
+		if a.Type == "" {
+			return nil, fmt.Errorf("missing attribute type")
+		}
+		if v.URL != nil {
+			if v.Value != nil || v.Base64 != nil {
+				return nil, fmt.Errorf("%s: expected either a value or an url", a.Type)
+			}
+			a.Value, a.URL = *v.URL, true
+		} else {
+			value, err := v.value()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", a.Type, err)
+			}
+			a.Value = value
+		}
+		attributes = append(attributes, a)
+	}
+	return attributes, nil
+}
+
+func (v jsonValue) value() (string, error) {
+	switch {
+	case v.Value != nil && v.Base64 == nil:
+		return *v.Value, nil
+	case v.Base64 != nil && v.Value == nil:
+		raw, err := base64.StdEncoding.Strict().DecodeString(*v.Base64)
+		return string(raw), err
+	default:
+		return "", fmt.Errorf("expected either a value or a base64 value")
+	}
+}
@@ -0,0 +1,152 @@
+package ldif
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// mappingRecords are records that use all fields of the JSON and YAML schema.
+var mappingRecords = []Record{
+	&Entry{
+		DN: "cn=Barbara Jensen, dc=airius, dc=com",
+		Attributes: []Attribute{
+			{Type: "cn", Value: "Barbara Jensen"},
+			{Type: "cn;lang-en", Value: "Babs"},
+			{Type: "description", Value: ""},
+			{Type: "userCertificate;binary", Value: "0\x82"},
+			{Type: "jpegphoto", Value: "\xff\xd8\xff"},
+			{Type: "jpegphoto", Value: "file:///photos/babs.jpg", URL: true},
+		},
+		Comments: []string{" a comment"},
+	},
+}
+
+var mappingChanges = []Record{
+	&AddRecord{
+		Change: Change{
+			DN:       "cn=Fiona Jensen, dc=airius, dc=com",
+			Controls: []Control{{OID: "1.3.6.1.4.1.4203.1.10.2", Criticality: true}},
+		},
+		Attributes: []Attribute{{Type: "cn", Value: "Fiona Jensen"}},
+	},
+	&DeleteRecord{
+		Change: Change{
+			DN:       "ou=Product Development, dc=airius, dc=com",
+			Controls: []Control{{OID: "1.2.840.113556.1.4.805", Criticality: true, Value: "\x30\x00"}, {OID: "1.2.3", Value: "text"}, {OID: "1.2.4", Value: "file:///control", URL: true}},
+		},
+	},
+	&ModifyRecord{
+		Change: Change{DN: "cn=Paula Jensen, dc=airius, dc=com"},
+		Modifications: []Modification{
+			{Type: ModAdd, Attribute: "postaladdress", Values: []Attribute{{Type: "postaladdress", Value: "123 Anystreet"}}},
+			{Type: ModDelete, Attribute: "description", Values: []Attribute{}},
+			{Type: ModReplace, Attribute: "cn", Values: []Attribute{{Type: "cn;lang-en", Value: "Paula"}}},
+		},
+	},
+	&ModDNRecord{
+		Change:       Change{DN: "ou=PD Accountants, dc=airius, dc=com"},
+		NewRDN:       "ou=Accountants",
+		DeleteOldRDN: true,
+		NewSuperior:  "ou=Accounting, dc=airius, dc=com",
+	},
+}
+
+func TestJSON(t *testing.T) {
+	data, err := MarshalJSON(mappingRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[
+  {
+    "dn": "cn=Barbara Jensen, dc=airius, dc=com",
+    "attributes": [
+      {
+        "type": "cn",
+        "value": "Barbara Jensen"
+      },
+      {
+        "type": "cn;lang-en",
+        "value": "Babs"
+      },
+      {
+        "type": "description",
+        "value": ""
+      },
+      {
+        "type": "userCertificate;binary",
+        "base64": "MII="
+      },
+      {
+        "type": "jpegphoto",
+        "base64": "/9j/"
+      },
+      {
+        "type": "jpegphoto",
+        "url": "file:///photos/babs.jpg"
+      }
+    ],
+    "comments": [
+      " a comment"
+    ]
+  }
+]`; string(data) != expected {
+		t.Errorf("unexpected json:\n%s", data)
+	}
+
+	for _, records := range [][]Record{mappingRecords, mappingChanges} {
+		data, err := MarshalJSON(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := UnmarshalJSON(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed, records) {
+			t.Errorf("records changed after a round trip:\n%s", data)
+		}
+	}
+}
+
+func TestJSONExamples(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.ldif")
+	for _, file := range files {
+		raw, _ := ioutil.ReadFile(file)
+		records, err := Parse(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := MarshalJSON(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := UnmarshalJSON(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed, records) {
+			t.Errorf("%s: records changed after a round trip:\n%s", file, data)
+		}
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{}`,
+		`[{"dn": "cn=a"}]`,
+		`[{"dn": "cn=a", "attributes": [{"value": "a"}]}]`,
+		`[{"dn": "cn=a", "attributes": [{"type": "cn"}]}]`,
+		`[{"dn": "cn=a", "attributes": [{"type": "cn", "value": "a", "base64": "YQ=="}]}]`,
+		`[{"dn": "cn=a", "attributes": [{"type": "cn", "value": "a", "url": "file:///a"}]}]`,
+		`[{"dn": "cn=a", "attributes": [{"type": "cn", "base64": "YQ"}]}]`,
+		`[{"dn": "cn=a", "changetype": "remove"}]`,
+		`[{"dn": "cn=a", "changetype": "modrdn"}]`,
+		`[{"dn": "cn=a", "changetype": "modify", "modifications": [{"op": "increment", "attribute": "a"}]}]`,
+	} {
+		if _, err := UnmarshalJSON([]byte(data)); err == nil {
+			t.Errorf("no error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package ldif
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+// Parse parses the given LDIF file and returns its records. The records are
+// either all of type *Entry, or all change records. Folded lines are unfolded
+// before the file is parsed. The file has to start with a version-spec of
+// version 1, use ParseLenient to parse files without a version-spec.
+//
+// If the file is not valid, the returned error is a *ParseError.
+func Parse(s string) ([]Record, error) {
+	records, ok := parse(s, false)
+	if !ok {
+		return nil, diagnoseFile(s, false)
+	}
+	return records, nil
+}
+
+// ParseLenient parses the given LDIF file like Parse, but the version-spec is
+// optional and its version number is not validated, like a lenient Reader.
+func ParseLenient(s string) ([]Record, error) {
+	records, ok := parse(s, true)
+	if !ok {
+		return nil, diagnoseFile(s, true)
+	}
+	return records, nil
+}
+
+func parse(s string, lenient bool) ([]Record, bool) {
+	s = unfold(s)
+	if !strings.HasSuffix(s, "\n") {
+		// the last line of the last record still needs its separator
+		s += "\n"
+	}
+	input := []rune(s)
+	rule := File
+	if lenient {
+		rule = LenientFile
+	}
+	file := longest(rule(input))
+	if file == nil {
+		return nil, false
+	}
+	if rest := input[len(file.Value):]; !matches(Repeat0Inf(`*SEP`, sep), string(rest)) {
+		return nil, false
+	}
+	if !lenient && checkVersion(file.GetSubNode(`version-number`).String()) != nil {
+		return nil, false
+	}
+
+	root, key := file.GetSubNode(`ldif-content`), `ldif-attrval-record`
+	if root == nil {
+		root, key = file.GetSubNode(`ldif-changes`), `ldif-change-record`
+	}
+	nodes, comments := recordNodes(root, key)
+	records := make([]Record, 0, len(nodes))
+	for i, node := range nodes {
+		record, err := newRecord(node, comments[i])
+		if err != nil {
+			return nil, false
+		}
+		records = append(records, record)
+	}
+	return records, true
+}
+
+// recordNodes returns the record nodes with the given key within n, with their
+// comments. The comments in the separators before a record, or at the start of
+// the file, belong to the record.
+func recordNodes(n *Node, key string) ([]*Node, [][]string) {
+	var nodes []*Node
+	var comments [][]string
+	var preceding []string
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		switch n.Key {
+		case key:
+			nodes = append(nodes, n)
+			comments = append(comments, append(preceding, commentLines(n)...))
+			preceding = nil
+		case `comment-line`:
+			preceding = append(preceding, commentText(n))
+		default:
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return nodes, comments
+}
+
+// diagnoseFile returns the first error in the given invalid LDIF file. The file
+// is read record by record, which allows to pinpoint the error.
+func diagnoseFile(s string, lenient bool) error {
+	r := NewReader(strings.NewReader(s))
+	r.Lenient = lenient
+	for r.Next() {
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	return &ParseError{
+		Line:   1,
+		Column: 1,
+		Rule:   `ldif-file`,
+	}
+}
+
+// checkVersion checks whether the version number is supported, only version 1
+// is defined by RFC2849.
+func checkVersion(number string) error {
+	if number != "1" {
+		return fmt.Errorf("unsupported ldif version %s, expected version 1", number)
+	}
+	return nil
+}
+
+// unfold joins all folded lines. A line is folded by inserting a separator
+// followed by a single space, the continuation line. Like the Reader, a line
+// that starts with a space is only a continuation of a preceding non-empty
+// line, otherwise it is left as is.
+func unfold(s string) string {
+	var lines []string
+	for _, l := range strings.SplitAfter(s, "\n") {
+		if last := len(lines) - 1; strings.HasPrefix(l, " ") && 0 <= last && lines[last] != "\n" && lines[last] != "\r\n" {
+			lines[last] = strings.TrimRight(lines[last], "\r\n") + l[1:]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return strings.Join(lines, "")
+}
+
+// longest returns the longest alternative. A file containing only records that
+// are valid in both an ldif-content and an ldif-changes file (e.g. a record with
+// a "changetype: delete" line) is ambiguous, the last alternative is preferred
+// in that case since an attribute named "changetype" is not meant to be.
+func longest(as Alternatives) *Node {
+	var best *Node
+	for _, a := range as {
+		if best == nil || len(best.Value) <= len(a.Value) {
+			best = a
+		}
+	}
+	return best
+}
+
+// matches checks whether the given rule matches the complete string.
+func matches(rule Operator, s string) bool {
+	input := []rune(s)
+	for _, a := range rule(input) {
+		if len(a.Value) == len(input) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRecord converts an ldif-attrval-record or ldif-change-record node to a
+// record with the given comments.
+func newRecord(n *Node, comments []string) (Record, error) {
+	dn, err := distinguishedNameValue(n.GetImmediateSubNode(`dn-spec`))
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Key == `ldif-attrval-record` {
+		attributes, err := newAttributes(n.GetSubNodes(`attrval-spec`))
+		if err != nil {
+			return nil, err
+		}
+		// these are valid attrval-specs, yet they are meant to start a change
+		// record that turned out to be invalid
+		if t := attributes[0].Type; t == "control" || t == "changetype" {
+			return nil, fmt.Errorf("invalid change record")
+		}
+		return &Entry{
+			DN:         dn,
+			Attributes: attributes,
+			Comments:   comments,
+		}, nil
+	}
+
+	change := Change{DN: dn, Comments: comments}
+	for _, c := range n.GetSubNodes(`control`) {
+		control, err := newControl(c)
+		if err != nil {
+			return nil, err
+		}
+		change.Controls = append(change.Controls, control)
+	}
+
+	cr := n.GetImmediateSubNode(`changerecord`)
+	if add := cr.GetSubNode(`change-add`); add != nil {
+		attributes, err := newAttributes(add.GetSubNodes(`attrval-spec`))
+		if err != nil {
+			return nil, err
+		}
+		return &AddRecord{
+			Change:     change,
+			Attributes: attributes,
+		}, nil
+	}
+	if cr.GetSubNode(`change-delete`) != nil {
+		return &DeleteRecord{
+			Change: change,
+		}, nil
+	}
+	if modify := cr.GetSubNode(`change-modify`); modify != nil {
+		record := ModifyRecord{Change: change}
+		for _, spec := range modify.GetSubNodes(`mod-spec`) {
+			values, err := newAttributes(spec.GetSubNodes(`attrval-spec`))
+			if err != nil {
+				return nil, err
+			}
+			typ := spec.GetImmediateSubNode(`"add:" / "delete:" / "replace:"`).String()
+			record.Modifications = append(record.Modifications, Modification{
+				Type:      ModType(strings.TrimSuffix(typ, ":")),
+				Attribute: spec.GetImmediateSubNode(`AttributeDescription`).String(),
+				Values:    values,
+			})
+		}
+		return &record, nil
+	}
+
+	moddn := cr.GetSubNode(`change-moddn`)
+	newRDN, err := distinguishedNameValue(moddn.GetSubNode(`FILL rdn / ":" FILL base64-rdn`))
+	if err != nil {
+		return nil, err
+	}
+	record := ModDNRecord{
+		Change:       change,
+		NewRDN:       newRDN,
+		DeleteOldRDN: moddn.GetSubNode(`"0" / "1"`).String() == "1",
+	}
+	if superior := moddn.GetSubNode(`FILL distinguishedName / ":" FILL base64-distinguishedName`); superior != nil {
+		if record.NewSuperior, err = distinguishedNameValue(superior); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+// commentLines returns the text of all comment lines within the given node.
+func commentLines(n *Node) []string {
+	var comments []string
+	for _, c := range n.GetSubNodes(`comment-line`) {
+		comments = append(comments, commentText(c))
+	}
+	return comments
+}
+
+// commentText returns the text of a comment-line, without the "#".
+func commentText(n *Node) string {
+	return strings.TrimRight(string(n.Value[1:]), "\r\n")
+}
+
+func newAttributes(nodes Alternatives) ([]Attribute, error) {
+	attributes := make([]Attribute, 0, len(nodes))
+	for _, n := range nodes {
+		value, url, err := valueSpecValue(n.GetImmediateSubNode(`value-spec`))
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, Attribute{
+			Type:  n.GetImmediateSubNode(`AttributeDescription`).String(),
+			Value: value,
+			URL:   url,
+		})
+	}
+	return attributes, nil
+}
+
+func newControl(n *Node) (Control, error) {
+	control := Control{
+		OID: n.GetImmediateSubNode(`ldap-oid`).String(),
+	}
+	if criticality := n.GetSubNode(`"true" / "false"`); criticality != nil {
+		control.Criticality = criticality.String() == "true"
+	}
+	if spec := n.GetSubNode(`value-spec`); spec != nil {
+		value, url, err := valueSpecValue(spec)
+		if err != nil {
+			return Control{}, err
+		}
+		control.Value, control.URL = value, url
+	}
+	return control, nil
+}
+
+// valueSpecValue returns the value of a value-spec, and whether it is an url.
+func valueSpecValue(n *Node) (string, bool, error) {
+	if b := n.GetSubNode(`BASE64-STRING`); b != nil {
+		value, err := decodeBase64(b)
+		return value, false, err
+	}
+	if u := n.GetSubNode(`url`); u != nil {
+		return u.String(), true, nil
+	}
+	if safe := n.GetSubNode(`SAFE-STRING`); safe != nil {
+		return safe.String(), false, nil
+	}
+	return "", false, nil
+}
+
+// distinguishedNameValue returns the (decoded) distinguished name of a node
+// that contains either a distinguishedName or a base64-distinguishedName, or
+// either an rdn or a base64-rdn.
+func distinguishedNameValue(n *Node) (string, error) {
+	if b := n.GetSubNode(`BASE64-STRING`); b != nil {
+		return decodeBase64UTF8(b)
+	}
+	return n.GetSubNode(`SAFE-STRING`).String(), nil
+}
+
+// decodeBase64 decodes a BASE64-STRING. The encoded value needs to be padded
+// and can not contain any trailing bits.
+func decodeBase64(n *Node) (string, error) {
+	raw, err := base64.StdEncoding.Strict().DecodeString(n.String())
+	return string(raw), err
+}
+
+// decodeBase64UTF8 decodes a BASE64-UTF8-STRING, which is a BASE64-STRING that
+// must be the base64 encoding of a UTF8-STRING.
+func decodeBase64UTF8(n *Node) (string, error) {
+	value, err := decodeBase64(n)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.ValidString(value) {
+		return "", fmt.Errorf("decoded value is not a valid UTF-8 string")
+	}
+	return value, nil
+}
@@ -0,0 +1,328 @@
+package ldif
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elimity-com/ldif/dn3"
+)
+
+func TestParseContent(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/example1.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 2 {
+		t.Fatalf("did not find 2 records, got %d", l)
+	}
+
+	entry, ok := records[0].(*Entry)
+	if !ok {
+		t.Fatalf("expected an entry, got %T", records[0])
+	}
+	if dn := entry.DN; dn != "cn=Barbara Jensen, ou=Product Development, dc=airius, dc=com" {
+		t.Errorf("unexpected dn: %s", dn)
+	}
+	if l := len(entry.Attributes); l != 10 {
+		t.Errorf("did not find 10 attributes, got %d", l)
+	}
+	if cn := entry.Values("CN"); len(cn) != 3 || cn[2] != "Babs Jensen" {
+		t.Errorf("unexpected cn values: %v", cn)
+	}
+}
+
+func TestParseBase64(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/example3.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	description := records[0].(*Entry).Values("description")
+	if len(description) != 1 || description[0][:22] != "What a careful reader " {
+		t.Errorf("description was not decoded: %v", description)
+	}
+}
+
+func TestParseFolded(t *testing.T) {
+	var expected []Record
+	for _, file := range []string{
+		"testdata/example2.ldif",
+		"testdata/example3.ldif",
+	} {
+		raw, _ := ioutil.ReadFile(file)
+		records, err := Parse(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, records...)
+	}
+
+	raw, _ := ioutil.ReadFile("testdata/folded.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, records) {
+		t.Errorf("folded records do not match the unfolded ones: %v", records)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/comments.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 2 {
+		t.Fatalf("did not find 2 records, got %d", l)
+	}
+
+	record := records[0].(*DeleteRecord)
+	if !reflect.DeepEqual(record.Comments, []string{
+		" A comment before the version-spec",
+		" Delete an entry. The operation will attach the LDAPv3",
+		" Tree Delete Control.",
+		" a comment within a record",
+	}) {
+		t.Errorf("unexpected comments: %q", record.Comments)
+	}
+	if c := record.Controls[0]; c.OID != "1.2.840.113556.1.4.805" || !c.Criticality {
+		t.Errorf("unexpected control: %v", c)
+	}
+
+	add := records[1].(*AddRecord)
+	if l := len(add.Attributes); l != 2 {
+		t.Errorf("did not find 2 attributes, got %d", l)
+	}
+	// the second comment is folded
+	if !reflect.DeepEqual(add.Comments, []string{
+		" The comment below is folded.",
+		" title;lang-ja;phonetic:: <JapaneseTitle_in_phonetic_representation_kana>",
+	}) {
+		t.Errorf("unexpected comments: %q", add.Comments)
+	}
+}
+
+func TestParseBase64UTF8(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/example4.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dn := records[1].DistinguishedName(); dn != "uid=rogasawara,ou=営業部,o=Airius" {
+		t.Errorf("unexpected dn: %s", dn)
+	}
+
+	// attribute values do not have to be valid UTF-8
+	records, err = Parse("version: 1\ndn:: Y249YQ==\njpegphoto:: /9j/\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if photo := records[0].(*Entry).Values("jpegphoto"); len(photo) != 1 || photo[0] != "\xff\xd8\xff" {
+		t.Errorf("unexpected value: %q", photo)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/example6.ldif")
+	records, err := Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 6 {
+		t.Fatalf("did not find 6 records, got %d", l)
+	}
+	add := records[0].(*AddRecord)
+	if photo := add.Attributes[len(add.Attributes)-1]; !photo.URL || photo.Value != "file:///usr/local/directory/photos/fiona.jpg" {
+		t.Errorf("unexpected url value: %v", photo)
+	}
+
+	for _, str := range []string{
+		"version: 1\ndn: cn=a\njpegphoto:< photo.jpg\n",
+		"version: 1\ndn: cn=a\njpegphoto:< file:///my photo.jpg\n",
+		"version: 1\ndn: cn=a\njpegphoto:< file:///photo%2.jpg\n",
+	} {
+		if _, err := Parse(str); err == nil {
+			t.Errorf("no error for: %q", str)
+		}
+	}
+}
+
+func TestParseChanges(t *testing.T) {
+	records, err := Parse(`version: 1
+dn: cn=Paula Jensen, ou=Product Development, dc=airius, dc=com
+control: 1.2 true
+changetype: modify
+add: postaladdress
+postaladdress: 123 Anystreet $ Sunnyvale, CA $ 94086
+-
+delete: description
+-
+
+dn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com
+changetype: delete
+
+dn: ou=PD Accountants, ou=Product Development, dc=airius, dc=com
+changetype: modrdn
+newrdn: ou=Product Development Accountants
+deleteoldrdn: 0
+newsuperior: ou=Accounting, dc=airius, dc=com
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(records); l != 3 {
+		t.Fatalf("did not find 3 records, got %d", l)
+	}
+
+	modify, ok := records[0].(*ModifyRecord)
+	if !ok {
+		t.Fatalf("expected a modify record, got %T", records[0])
+	}
+	if l := len(modify.Controls); l != 1 || !modify.Controls[0].Criticality {
+		t.Errorf("unexpected controls: %v", modify.Controls)
+	}
+	if l := len(modify.Modifications); l != 2 {
+		t.Fatalf("did not find 2 modifications, got %d", l)
+	}
+	if m := modify.Modifications[0]; m.Type != ModAdd || m.Attribute != "postaladdress" || len(m.Values) != 1 {
+		t.Errorf("unexpected modification: %v", m)
+	}
+	if m := modify.Modifications[1]; m.Type != ModDelete || m.Attribute != "description" || len(m.Values) != 0 {
+		t.Errorf("unexpected modification: %v", m)
+	}
+
+	if _, ok := records[1].(*DeleteRecord); !ok {
+		t.Errorf("expected a delete record, got %T", records[1])
+	}
+
+	moddn, ok := records[2].(*ModDNRecord)
+	if !ok {
+		t.Fatalf("expected a moddn record, got %T", records[2])
+	}
+	if moddn.NewRDN != "ou=Product Development Accountants" || moddn.DeleteOldRDN || moddn.NewSuperior != "ou=Accounting, dc=airius, dc=com" {
+		t.Errorf("unexpected moddn record: %v", moddn)
+	}
+}
+
+func TestParseDistinguishedNames(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.ldif")
+	for _, file := range files {
+		raw, _ := ioutil.ReadFile(file)
+		records, err := Parse(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range records {
+			dns := []string{r.DistinguishedName()}
+			if moddn, ok := r.(*ModDNRecord); ok {
+				dns = append(dns, moddn.NewRDN, moddn.NewSuperior)
+			}
+			for _, dn := range dns {
+				if _, err := dn3.ParseDN(dn); err != nil {
+					t.Errorf("%s: %v", file, err)
+				}
+			}
+		}
+	}
+}
+
+func TestParseAmbiguous(t *testing.T) {
+	// "changetype: delete" is also a valid attrval-spec
+	records, err := Parse("version: 1\ndn: cn=Robert Jensen, ou=Marketing, dc=airius, dc=com\nchangetype: delete\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := records[0].(*DeleteRecord); !ok {
+		t.Errorf("expected a delete record, got %T", records[0])
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, str := range []string{
+		"",
+		"dn: cn=no version\ncn: no version\n",
+		"version: 1\ndn: cn=no attributes\n",
+		"version: 2\ndn: cn=unsupported version\ncn: unsupported version\n",
+		"version: 1\ndn: cn=invalid base64\ncn:: abc\n",
+	} {
+		if _, err := Parse(str); err == nil {
+			t.Errorf("no error for: %q", str)
+		}
+	}
+}
+
+// TestParseReader checks that Parse and the Reader agree on the same input.
+func TestParseReader(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		valid bool
+	}{
+		{"version: 1\ndn: cn=a,\n dc=com\ncn: a\n\ndn: cn=b\ncn: b\n", true},
+		// a continuation line can not follow an empty line
+		{"version: 1\ndn: cn=a\ncn: a\n\n dn: cn=b\ncn: b\n", false},
+		{"version: 1\n\n dn: cn=a\ncn: a\n", false},
+	} {
+		records, err := Parse(test.input)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: unexpected parse error: %v", test.input, err)
+		}
+
+		var read []Record
+		r := NewReader(strings.NewReader(test.input))
+		for r.Next() {
+			read = append(read, r.Record())
+		}
+		if (r.Err() == nil) != test.valid {
+			t.Errorf("%q: unexpected read error: %v", test.input, r.Err())
+		}
+		if test.valid && !reflect.DeepEqual(records, read) {
+			t.Errorf("%q: parsed %v, read %v", test.input, records, read)
+		}
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	for _, input := range []string{
+		"dn: cn=a\ncn: a\n\ndn: cn=b\ncn: b\n",
+		"# comment\n\ndn: cn=a\ncn: a\n",
+		"version: 1\ndn: cn=a\ncn: a\n",
+		"version: 2\n\ndn: cn=a\ncn: a\n",
+		"dn: cn=a\nchangetype: delete\n",
+	} {
+		records, err := ParseLenient(input)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", input, err)
+			continue
+		}
+
+		// the lenient Reader returns the same records
+		var read []Record
+		r := NewReader(strings.NewReader(input))
+		r.Lenient = true
+		for r.Next() {
+			read = append(read, r.Record())
+		}
+		if err := r.Err(); err != nil {
+			t.Errorf("could not read %q: %v", input, err)
+		}
+		if !reflect.DeepEqual(records, read) {
+			t.Errorf("%q: parsed %v, read %v", input, records, read)
+		}
+	}
+
+	for _, input := range []string{
+		"",
+		"# comment\n",
+		"version: 1\n\n",
+		"dn: cn=no attributes\n",
+	} {
+		if _, err := ParseLenient(input); err == nil {
+			t.Errorf("no error for: %q", input)
+		}
+	}
+}
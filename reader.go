@@ -0,0 +1,244 @@
+package ldif
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+// Reader reads the records of an LDIF file one at a time. The input is split on
+// the separators between the records, so only a single record is kept in
+// memory.
+//
+//	r := ldif.NewReader(f)
+//	for r.Next() {
+//		record := r.Record()
+//		...
+//	}
+//	if err := r.Err(); err != nil {
+//		...
+//	}
+type Reader struct {
+	// Lenient makes the version-spec optional, as many tools omit it. The
+	// version number is not validated either if Lenient is true.
+	Lenient bool
+	// Resolver, if not nil, is used to load the attribute values that are
+	// referenced by an URL.
+	Resolver URLResolver
+	// MergeRanges merges the chunks of attributes with a range option, as in
+	// "member;range=0-1499", into a single attribute. See MergeRanges.
+	MergeRanges bool
+
+	r *bufio.Reader
+	// line is the number of lines that have been read.
+	line int
+
+	// version indicates whether the version-spec has been read.
+	version bool
+	// records is the number of records that have been read.
+	records int
+	// changes indicates whether the records are change records.
+	changes bool
+	record  Record
+	err     error
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next reads the next record, which will then be available through the Record
+// method. It returns false when there are no more records, either by reaching
+// the end of the input or an error.
+func (r *Reader) Next() bool {
+	r.record = nil
+	if r.err != nil {
+		return false
+	}
+
+	// comments are the lines of the preceding chunks that only contain comments
+	var comments []line
+	for {
+		lines, err := r.readLines()
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if len(lines) == 0 {
+			eof := line{number: r.line + 1}
+			if !r.version && !r.Lenient {
+				r.err = eof.newParseError(0, "", `version-spec`, nil)
+			} else if r.records == 0 {
+				r.err = eof.newParseError(0, "", `dn-spec`, nil)
+			}
+			return false
+		}
+
+		i := skipComments(lines)
+		if !r.version && i != len(lines) {
+			r.version = true
+			text := []rune(strings.TrimRight(lines[i].text, "\r\n"))
+			if spec := longest(versionSpec(text)); spec != nil && len(spec.Value) == len(text) {
+				number := spec.GetSubNode(`version-number`)
+				if err := checkVersion(number.String()); err != nil && !r.Lenient {
+					r.err = lines[i].newParseError(len(text)-len(number.Value), "", `version-number`, err)
+					return false
+				}
+				// the version-spec can be directly followed by the first record
+				lines = append(lines[:i:i], lines[i+1:]...)
+				i = skipComments(lines)
+			} else if !r.Lenient {
+				r.err = lines[i].newParseError(0, "", `version-spec`, nil)
+				return false
+			}
+		}
+		if i == len(lines) {
+			comments = append(comments, lines...)
+			continue
+		}
+
+		record, err := parseRecord(append(comments, lines...))
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if r.Resolver != nil {
+			if err := ResolveURLs(record, r.Resolver); err != nil {
+				r.err = fmt.Errorf("line %d: %v", lines[i].number, err)
+				return false
+			}
+		}
+
+		if r.MergeRanges {
+			if err := MergeRanges(record); err != nil {
+				r.err = fmt.Errorf("line %d: %v", lines[i].number, err)
+				return false
+			}
+		}
+
+		_, entry := record.(*Entry)
+		if r.records != 0 && r.changes == entry {
+			rule := `ldif-change-record`
+			if r.changes {
+				rule = `ldif-attrval-record`
+			}
+			r.err = lines[i].newParseError(0, record.DistinguishedName(), rule, fmt.Errorf("ldif file contains both entries and change records"))
+			return false
+		}
+		r.changes = !entry
+		r.records++
+		r.record = record
+		return true
+	}
+}
+
+// Record returns the record that was read by the last call to Next.
+func (r *Reader) Record() Record {
+	return r.record
+}
+
+// Err returns the first error that was encountered by the Reader.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// readLines reads the lines of the next record, skipping any leading
+// separators. Folded lines are unfolded. The last line always ends with a
+// separator.
+func (r *Reader) readLines() ([]line, error) {
+	var lines []line
+	for {
+		text, err := r.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if text == "" {
+			return lines, nil
+		}
+		r.line++
+
+		if text == "\n" || text == "\r\n" {
+			if len(lines) != 0 {
+				return lines, nil
+			}
+			continue
+		}
+		if err == io.EOF {
+			// the last line of the last record still needs its separator
+			text += "\n"
+		}
+		if strings.HasPrefix(text, " ") && len(lines) != 0 {
+			// continuation of a folded line
+			last := &lines[len(lines)-1]
+			last.text = strings.TrimRight(last.text, "\r\n")
+			last.folds = append(last.folds, len([]rune(last.text)))
+			last.text += text[1:]
+		} else {
+			lines = append(lines, line{
+				text:   text,
+				number: r.line,
+			})
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+	}
+}
+
+// skipComments returns the index of the first line that is not a comment.
+func skipComments(lines []line) int {
+	for i, l := range lines {
+		if !strings.HasPrefix(l.text, "#") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// parseRecord parses the lines of a single ldif-attrval-record or
+// ldif-change-record, which can be preceded by comment lines.
+func parseRecord(lines []line) (Record, error) {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.text)
+	}
+	input := []rune(b.String())
+	node := longest(Concat(
+		`*comment-line record`,
+		Repeat0Inf(`*comment-line`, commentLine),
+		Alts(
+			`ldif-attrval-record / ldif-change-record`,
+			ldifAttrvalRecord,
+			ldifChangeRecord,
+		),
+	)(input))
+	if node == nil || len(node.Value) != len(input) {
+		return nil, diagnosisError(lines)
+	}
+
+	record := node.GetSubNode(`ldif-attrval-record`)
+	if record == nil {
+		record = node.GetSubNode(`ldif-change-record`)
+	}
+	r, err := newRecord(record, commentLines(node))
+	if err != nil {
+		return nil, diagnosisError(lines)
+	}
+	return r, nil
+}
+
+// diagnosisError returns the first error in the lines of a record. If the
+// error could not be found, an error at the start of the record is returned.
+func diagnosisError(lines []line) *ParseError {
+	if err := diagnose(lines); err != nil {
+		return err
+	}
+	l := lines[skipComments(lines)]
+	return l.newParseError(0, "", `ldif-attrval-record / ldif-change-record`, nil)
+}
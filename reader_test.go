@@ -0,0 +1,147 @@
+package ldif
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	f, err := os.Open("testdata/example1.ldif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var dns []string
+	r := NewReader(f)
+	for r.Next() {
+		dns = append(dns, r.Record().DistinguishedName())
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(dns) != 2 {
+		t.Fatalf("did not find 2 records, got %d", len(dns))
+	}
+	if dns[1] != "cn=Bjorn Jensen, ou=Accounting, dc=airius, dc=com" {
+		t.Errorf("unexpected dn: %s", dns[1])
+	}
+}
+
+func TestReaderFolded(t *testing.T) {
+	r := NewReader(strings.NewReader("version: 1\ndn: cn=Barbara Jensen,\n  dc=airius, dc=com\ndescription:: V2hhdCBhIGNhcmVmdWwgcmVh\n ZGVyIHlvdSBhcmUh\ncn: Barbara\n  Jensen\n"))
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	entry := r.Record().(*Entry)
+	if entry.DN != "cn=Barbara Jensen, dc=airius, dc=com" {
+		t.Errorf("unexpected dn: %s", entry.DN)
+	}
+	if d := entry.Values("description"); len(d) != 1 || d[0] != "What a careful reader you are!" {
+		t.Errorf("unexpected description: %v", d)
+	}
+	if cn := entry.Values("cn"); len(cn) != 1 || cn[0] != "Barbara Jensen" {
+		t.Errorf("unexpected cn: %v", cn)
+	}
+}
+
+func TestReaderComments(t *testing.T) {
+	r := NewReader(strings.NewReader(`# before version
+version: 1
+# after version
+
+# first
+dn: cn=a
+# inside
+cn: a
+
+# only comments
+
+# second
+dn: cn=b
+cn: b
+
+# trailing
+`))
+	var comments [][]string
+	for r.Next() {
+		comments = append(comments, r.Record().(*Entry).Comments)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(comments, [][]string{
+		{" before version", " after version", " first", " inside"},
+		{" only comments", " second"},
+	}) {
+		t.Errorf("unexpected comments: %q", comments)
+	}
+}
+
+func TestReaderChanges(t *testing.T) {
+	r := NewReader(strings.NewReader("version: 1\r\n\r\n\r\ndn: cn=a\r\nchangetype: delete\r\n\r\ndn: cn=b\r\nchangetype: add\r\ncn: b"))
+	var records []Record
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("did not find 2 records, got %d", len(records))
+	}
+	if _, ok := records[0].(*DeleteRecord); !ok {
+		t.Errorf("expected a delete record, got %T", records[0])
+	}
+	if add, ok := records[1].(*AddRecord); !ok || add.Values("cn")[0] != "b" {
+		t.Errorf("unexpected add record: %v", records[1])
+	}
+}
+
+func TestReaderLenient(t *testing.T) {
+	for _, input := range []string{
+		"dn: cn=a\ncn: a\n",
+		"# comment\n\ndn: cn=a\ncn: a\n",
+		"version: 1\ndn: cn=a\ncn: a\n",
+		"version: 2\ndn: cn=a\ncn: a\n",
+	} {
+		r := NewReader(strings.NewReader(input))
+		r.Lenient = true
+		if !r.Next() {
+			t.Errorf("could not read %q: %v", input, r.Err())
+			continue
+		}
+		if dn := r.Record().DistinguishedName(); dn != "cn=a" {
+			t.Errorf("unexpected dn: %s", dn)
+		}
+	}
+
+	r := NewReader(strings.NewReader("# comment\n"))
+	r.Lenient = true
+	if r.Next() || r.Err() == nil {
+		t.Error("expected at least one record")
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		err   string
+	}{
+		{"", "line 1, column 1: expected version-spec"},
+		{"dn: cn=a\ncn: a\n", `line 1, column 1: expected version-spec: "dn: cn=a"`},
+		{"version: 1\n\n", "line 3, column 1: expected dn-spec"},
+		{"# comment\nversion: 2\ndn: cn=a\ncn: a\n", `line 2, column 10: invalid version-number: "version: 2": unsupported ldif version 2, expected version 1`},
+		{"version: 1\ndn: cn=a\ncn: a\n\ndn: cn=b\n-\n", `line 6, column 1: expected attrval-spec (dn: cn=b): "-"`},
+		{"version: 1\ndn: cn=a\ncn: a\n\ndn: cn=b\nchangetype: delete\n", `line 5, column 1: invalid ldif-change-record (dn: cn=b): "dn: cn=b": ldif file contains both entries and change records`},
+	} {
+		r := NewReader(strings.NewReader(test.input))
+		for r.Next() {
+		}
+		if err := r.Err(); err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q for %q, got %v", test.err, test.input, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ldif
+
+// Record is a single LDIF record. It is either an *Entry, for records of an
+// ldif-content file, or one of *AddRecord, *DeleteRecord, *ModifyRecord and
+// *ModDNRecord, for records of an ldif-changes file.
+type Record interface {
+	// DistinguishedName returns the distinguished name of the entry the
+	// record describes.
+	DistinguishedName() string
+}
+
+// Entry is a directory entry, described by an ldif-attrval-record.
+type Entry struct {
+	DN         string
+	Attributes []Attribute
+	// Comments are the comment lines that precede or are part of the record,
+	// without their leading "#".
+	Comments []string
+}
+
+// DistinguishedName returns the distinguished name of the entry.
+func (e *Entry) DistinguishedName() string {
+	return e.DN
+}
+
+// Values returns all values of the attributes with the given attribute
+// description, including its options, if any. See AttributeDescription.Equal.
+func (e *Entry) Values(typ string) []string {
+	return values(e.Attributes, typ)
+}
+
+// Attribute is a single attribute value pair, described by an attrval-spec.
+type Attribute struct {
+	// Type is the attribute description, including its options. (e.g. "cn" or
+	// "cn;lang-ja")
+	Type string
+	// Value is the value of the attribute. Base64 encoded values are decoded.
+	// If URL is true, the value is the URL that references the actual value.
+	Value string
+	URL   bool
+}
+
+// Control is an LDAP control that is attached to a change record.
+type Control struct {
+	OID         string
+	Criticality bool
+	// Value is the value of the control, it is empty if the control has no
+	// value.
+	Value string
+	// URL is true if the value is an URL that refers to the value of the
+	// control, see ResolveURLs.
+	URL bool
+}
+
+// Change contains the fields that are common to all change records.
+type Change struct {
+	DN       string
+	Controls []Control
+	// Comments are the comment lines that precede or are part of the record,
+	// without their leading "#".
+	Comments []string
+}
+
+// DistinguishedName returns the distinguished name of the entry the change
+// applies to.
+func (c *Change) DistinguishedName() string {
+	return c.DN
+}
+
+// ChangeRecord is a record that describes a change to a directory entry. It is
+// one of *AddRecord, *DeleteRecord, *ModifyRecord and *ModDNRecord.
+type ChangeRecord interface {
+	Record
+	// Header returns the fields that are common to all change records.
+	Header() *Change
+}
+
+// Header returns the fields that are common to all change records.
+func (c *Change) Header() *Change {
+	return c
+}
+
+// AddRecord adds a new entry to the directory, described by a change-add.
+type AddRecord struct {
+	Change
+	Attributes []Attribute
+}
+
+// Values returns all values of the attributes with the given attribute
+// description, including its options, if any. See AttributeDescription.Equal.
+func (r *AddRecord) Values(typ string) []string {
+	return values(r.Attributes, typ)
+}
+
+// DeleteRecord deletes an entry from the directory, described by a
+// change-delete.
+type DeleteRecord struct {
+	Change
+}
+
+// ModifyRecord modifies the attributes of an entry, described by a
+// change-modify.
+type ModifyRecord struct {
+	Change
+	Modifications []Modification
+}
+
+// ModDNRecord renames and/or moves an entry, described by a change-moddn.
+type ModDNRecord struct {
+	Change
+	NewRDN       string
+	DeleteOldRDN bool
+	// NewSuperior is the distinguished name of the new parent of the entry, it
+	// is empty if the entry does not move.
+	NewSuperior string
+}
+
+// ModType is the type of modification of a mod-spec.
+type ModType string
+
+const (
+	ModAdd     ModType = "add"
+	ModDelete  ModType = "delete"
+	ModReplace ModType = "replace"
+)
+
+// Modification is a single modification of a change-modify, described by a
+// mod-spec.
+type Modification struct {
+	Type ModType
+	// Attribute is the attribute description of the modified attribute.
+	Attribute string
+	// Values are the attrval-specs of the modification, they can be empty.
+	Values []Attribute
+}
+
+func values(attributes []Attribute, typ string) []string {
+	d := newAttributeDescription(typ)
+	var vs []string
+	for _, a := range attributes {
+		if a.Description().Equal(d) {
+			vs = append(vs, a.Value)
+		}
+	}
+	return vs
+}
@@ -0,0 +1,123 @@
+package ldif
+
+import (
+	"fmt"
+	"io/ioutil"
+	neturl "net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// URLResolver loads the values that are referenced by an URL, as in
+// "jpegphoto:< file:///usr/local/directory/photos/fiona.jpg".
+type URLResolver interface {
+	Resolve(url string) ([]byte, error)
+}
+
+// URLResolverFunc is an adapter to use an ordinary function as URLResolver.
+type URLResolverFunc func(url string) ([]byte, error)
+
+// Resolve calls f(url).
+func (f URLResolverFunc) Resolve(url string) ([]byte, error) {
+	return f(url)
+}
+
+// SchemeResolver resolves URLs with the resolver that is registered for their
+// scheme (e.g. "file"). Schemes are lower case.
+type SchemeResolver map[string]URLResolver
+
+// Resolve resolves the URL with the resolver that is registered for its scheme.
+func (r SchemeResolver) Resolve(url string) ([]byte, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+	resolver, ok := r[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported url scheme: %q", u.Scheme)
+	}
+	return resolver.Resolve(url)
+}
+
+// FileResolver resolves "file" URLs to the content of the file they refer to.
+// The path of the URL is relative to the Root directory, files outside of that
+// directory can not be loaded.
+type FileResolver struct {
+	Root string
+}
+
+// Resolve reads the file the URL refers to.
+func (r FileResolver) Resolve(url string) ([]byte, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(u.Scheme, "file") {
+		return nil, fmt.Errorf("not a file url: %s", url)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("file url refers to a remote host: %s", url)
+	}
+
+	root, err := filepath.EvalSymlinks(r.Root)
+	if err != nil {
+		return nil, err
+	}
+	// cleaning the absolute path removes all leading ".." elements
+	name, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(path.Clean("/"+u.Path))))
+	if err != nil {
+		return nil, err
+	}
+	// symbolic links can still refer to files outside of the root
+	if rel, err := filepath.Rel(root, name); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file url refers to a file outside of %s: %s", r.Root, url)
+	}
+	return ioutil.ReadFile(name)
+}
+
+// ResolveURLs replaces all attribute values of the record that are referenced by
+// an URL with the values that are loaded by the resolver.
+func ResolveURLs(record Record, resolver URLResolver) error {
+	var attributes []Attribute
+	switch r := record.(type) {
+	case *Entry:
+		attributes = r.Attributes
+	case *AddRecord:
+		attributes = r.Attributes
+	case *ModifyRecord:
+		for _, m := range r.Modifications {
+			if err := resolveURLs(m.Values, resolver); err != nil {
+				return err
+			}
+		}
+	}
+	if r, ok := record.(ChangeRecord); ok {
+		controls := r.Header().Controls
+		for i, c := range controls {
+			if !c.URL {
+				continue
+			}
+			value, err := resolver.Resolve(c.Value)
+			if err != nil {
+				return fmt.Errorf("could not resolve the value of control %s: %v", c.OID, err)
+			}
+			controls[i].Value, controls[i].URL = string(value), false
+		}
+	}
+	return resolveURLs(attributes, resolver)
+}
+
+func resolveURLs(attributes []Attribute, resolver URLResolver) error {
+	for i, a := range attributes {
+		if !a.URL {
+			continue
+		}
+		value, err := resolver.Resolve(a.Value)
+		if err != nil {
+			return fmt.Errorf("could not resolve the value of %s: %v", a.Type, err)
+		}
+		attributes[i].Value, attributes[i].URL = string(value), false
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package ldif
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileResolver(t *testing.T) {
+	resolver := FileResolver{Root: "testdata"}
+	value, err := resolver.Resolve("file:///example7.ldif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(value), "version: 1\n") {
+		t.Errorf("unexpected value: %s", value)
+	}
+
+	for _, url := range []string{
+		"file:///../syntax_definition.go",
+		"file://remote/example7.ldif",
+		"http://localhost/example7.ldif",
+	} {
+		if _, err := resolver.Resolve(url); err == nil {
+			t.Errorf("no error for: %s", url)
+		}
+	}
+}
+
+func TestReaderResolver(t *testing.T) {
+	f, err := os.Open("testdata/example5.ldif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r := NewReader(f)
+	r.Resolver = SchemeResolver{
+		"file": URLResolverFunc(func(url string) ([]byte, error) {
+			return []byte(strings.TrimPrefix(url, "file:///usr/local/directory/photos/")), nil
+		}),
+	}
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	entry := r.Record().(*Entry)
+	photo := entry.Attributes[len(entry.Attributes)-1]
+	if photo.URL || photo.Value != "hjensen.jpg" {
+		t.Errorf("url was not resolved: %v", photo)
+	}
+}
+
+func TestResolveControlURLs(t *testing.T) {
+	const input = "version: 1\ndn: cn=a\ncontrol: 1.2.3 true:< file:///control\nchangetype: delete\n"
+	records, err := Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := records[0].(*DeleteRecord)
+	if c := record.Controls[0]; !c.URL || c.Value != "file:///control" {
+		t.Errorf("unexpected control: %v", c)
+	}
+
+	var b strings.Builder
+	w := NewWriter(&b)
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != input {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+
+	resolver := URLResolverFunc(func(url string) ([]byte, error) {
+		return []byte{0x30, 0x00}, nil
+	})
+	if err := ResolveURLs(record, resolver); err != nil {
+		t.Fatal(err)
+	}
+	if c := record.Controls[0]; c.URL || c.Value != "\x30\x00" {
+		t.Errorf("url was not resolved: %v", c)
+	}
+}
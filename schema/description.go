@@ -0,0 +1,375 @@
+package schema
+
+// RFC 4512: 4.1. Schema Definitions
+
+import (
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+func attributeTypeDescription(s []rune) Alternatives {
+	return Concat(
+		`AttributeTypeDescription`,
+		lparen,
+		wsp,
+		numericoid,
+		Optional(`[SP "NAME" SP qdescrs]`, Concat(
+			`SP "NAME" SP qdescrs`,
+			sp, String(`NAME`, "NAME"), sp, qdescrs,
+		)),
+		Optional(`[SP "DESC" SP qdstring]`, Concat(
+			`SP "DESC" SP qdstring`,
+			sp, String(`DESC`, "DESC"), sp, qdstring,
+		)),
+		Optional(`[SP "OBSOLETE"]`, Concat(
+			`SP "OBSOLETE"`,
+			sp, String(`OBSOLETE`, "OBSOLETE"),
+		)),
+		Optional(`[SP "SUP" SP oid]`, Concat(
+			`SP "SUP" SP oid`,
+			sp, String(`SUP`, "SUP"), sp, oid,
+		)),
+		Optional(`[SP "EQUALITY" SP oid]`, Concat(
+			`SP "EQUALITY" SP oid`,
+			sp, String(`EQUALITY`, "EQUALITY"), sp, oid,
+		)),
+		Optional(`[SP "ORDERING" SP oid]`, Concat(
+			`SP "ORDERING" SP oid`,
+			sp, String(`ORDERING`, "ORDERING"), sp, oid,
+		)),
+		Optional(`[SP "SUBSTR" SP oid]`, Concat(
+			`SP "SUBSTR" SP oid`,
+			sp, String(`SUBSTR`, "SUBSTR"), sp, oid,
+		)),
+		Optional(`[SP "SYNTAX" SP noidlen]`, Concat(
+			`SP "SYNTAX" SP noidlen`,
+			sp, String(`SYNTAX`, "SYNTAX"), sp, noidlen,
+		)),
+		Optional(`[SP "SINGLE-VALUE"]`, Concat(
+			`SP "SINGLE-VALUE"`,
+			sp, String(`SINGLE-VALUE`, "SINGLE-VALUE"),
+		)),
+		Optional(`[SP "COLLECTIVE"]`, Concat(
+			`SP "COLLECTIVE"`,
+			sp, String(`COLLECTIVE`, "COLLECTIVE"),
+		)),
+		Optional(`[SP "NO-USER-MODIFICATION"]`, Concat(
+			`SP "NO-USER-MODIFICATION"`,
+			sp, String(`NO-USER-MODIFICATION`, "NO-USER-MODIFICATION"),
+		)),
+		Optional(`[SP "USAGE" SP usage]`, Concat(
+			`SP "USAGE" SP usage`,
+			sp, String(`USAGE`, "USAGE"), sp, usage,
+		)),
+		extensions,
+		wsp,
+		rparen,
+	)(s)
+}
+
+func usage(s []rune) Alternatives {
+	return Alts(
+		`usage`,
+		String(`userApplications`, "userApplications"),
+		String(`directoryOperation`, "directoryOperation"),
+		String(`distributedOperation`, "distributedOperation"),
+		String(`dSAOperation`, "dSAOperation"),
+	)(s)
+}
+
+func objectClassDescription(s []rune) Alternatives {
+	return Concat(
+		`ObjectClassDescription`,
+		lparen,
+		wsp,
+		numericoid,
+		Optional(`[SP "NAME" SP qdescrs]`, Concat(
+			`SP "NAME" SP qdescrs`,
+			sp, String(`NAME`, "NAME"), sp, qdescrs,
+		)),
+		Optional(`[SP "DESC" SP qdstring]`, Concat(
+			`SP "DESC" SP qdstring`,
+			sp, String(`DESC`, "DESC"), sp, qdstring,
+		)),
+		Optional(`[SP "OBSOLETE"]`, Concat(
+			`SP "OBSOLETE"`,
+			sp, String(`OBSOLETE`, "OBSOLETE"),
+		)),
+		Optional(`[SP "SUP" SP oids]`, Concat(
+			`SP "SUP" SP oids`,
+			sp, String(`SUP`, "SUP"), sp, oids,
+		)),
+		Optional(`[SP kind]`, Concat(
+			`SP kind`,
+			sp, kind,
+		)),
+		Optional(`[SP "MUST" SP oids]`, Concat(
+			`SP "MUST" SP oids`,
+			sp, String(`MUST`, "MUST"), sp, oids,
+		)),
+		Optional(`[SP "MAY" SP oids]`, Concat(
+			`SP "MAY" SP oids`,
+			sp, String(`MAY`, "MAY"), sp, oids,
+		)),
+		extensions,
+		wsp,
+		rparen,
+	)(s)
+}
+
+func kind(s []rune) Alternatives {
+	return Alts(
+		`kind`,
+		String(`ABSTRACT`, "ABSTRACT"),
+		String(`STRUCTURAL`, "STRUCTURAL"),
+		String(`AUXILIARY`, "AUXILIARY"),
+	)(s)
+}
+
+func matchingRuleDescription(s []rune) Alternatives {
+	return Concat(
+		`MatchingRuleDescription`,
+		lparen,
+		wsp,
+		numericoid,
+		Optional(`[SP "NAME" SP qdescrs]`, Concat(
+			`SP "NAME" SP qdescrs`,
+			sp, String(`NAME`, "NAME"), sp, qdescrs,
+		)),
+		Optional(`[SP "DESC" SP qdstring]`, Concat(
+			`SP "DESC" SP qdstring`,
+			sp, String(`DESC`, "DESC"), sp, qdstring,
+		)),
+		Optional(`[SP "OBSOLETE"]`, Concat(
+			`SP "OBSOLETE"`,
+			sp, String(`OBSOLETE`, "OBSOLETE"),
+		)),
+		Concat(
+			`SP "SYNTAX" SP numericoid`,
+			sp, String(`SYNTAX`, "SYNTAX"), sp, numericoid,
+		),
+		extensions,
+		wsp,
+		rparen,
+	)(s)
+}
+
+// SyntaxDescription
+func ldapSyntaxDescription(s []rune) Alternatives {
+	return Concat(
+		`SyntaxDescription`,
+		lparen,
+		wsp,
+		numericoid,
+		Optional(`[SP "DESC" SP qdstring]`, Concat(
+			`SP "DESC" SP qdstring`,
+			sp, String(`DESC`, "DESC"), sp, qdstring,
+		)),
+		extensions,
+		wsp,
+		rparen,
+	)(s)
+}
+
+func extensions(s []rune) Alternatives {
+	return Repeat0Inf(`extensions`, Concat(
+		`SP xstring SP qdstrings`,
+		sp,
+		xstring,
+		sp,
+		qdstrings,
+	))(s)
+}
+
+func xstring(s []rune) Alternatives {
+	return Concat(
+		`xstring`,
+		Rune(`X`, 'X'),
+		hyphen,
+		Repeat1Inf(`1*(ALPHA / HYPHEN / USCORE)`, Alts(
+			`ALPHA / HYPHEN / USCORE`,
+			alpha,
+			hyphen,
+			Rune(`USCORE`, '_'),
+		)),
+	)(s)
+}
+
+func qdescrs(s []rune) Alternatives {
+	return Alts(
+		`qdescrs`,
+		qdescr,
+		Concat(
+			`LPAREN WSP qdescrlist WSP RPAREN`,
+			lparen,
+			wsp,
+			Optional(`qdescrlist`, Concat(
+				`qdescr *(SP qdescr)`,
+				qdescr,
+				Repeat0Inf(`*(SP qdescr)`, Concat(
+					`SP qdescr`,
+					sp,
+					qdescr,
+				)),
+			)),
+			wsp,
+			rparen,
+		),
+	)(s)
+}
+
+func qdescr(s []rune) Alternatives {
+	return Concat(`qdescr`, squote, descr, squote)(s)
+}
+
+func qdstrings(s []rune) Alternatives {
+	return Alts(
+		`qdstrings`,
+		qdstring,
+		Concat(
+			`LPAREN WSP qdstringlist WSP RPAREN`,
+			lparen,
+			wsp,
+			Optional(`qdstringlist`, Concat(
+				`qdstring *(SP qdstring)`,
+				qdstring,
+				Repeat0Inf(`*(SP qdstring)`, Concat(
+					`SP qdstring`,
+					sp,
+					qdstring,
+				)),
+			)),
+			wsp,
+			rparen,
+		),
+	)(s)
+}
+
+func qdstring(s []rune) Alternatives {
+	return Concat(`qdstring`, squote, dstring, squote)(s)
+}
+
+func dstring(s []rune) Alternatives {
+	return Repeat1Inf(`dstring`, Alts(
+		`QS / QQ / QUTF8`,
+		// "\5C" / "\5c"
+		Concat(`QS`, esc, Rune(`5`, '5'), Alts(`C`, Rune(`C`, 'C'), Rune(`c`, 'c'))),
+		// "\27"
+		Concat(`QQ`, esc, Rune(`2`, '2'), Rune(`7`, '7')),
+		// any character except QUOTE and "\"
+		Alts(
+			`QUTF8`,
+			Range(`%x00-26`, 0x00, 0x26),
+			Range(`%x28-5B`, 0x28, 0x5B),
+			Range(`%x5D-`, 0x5D, utf8.MaxRune),
+		),
+	))(s)
+}
+
+// RFC 4512: 1.4. Common ABNF Productions
+
+func oids(s []rune) Alternatives {
+	return Alts(
+		`oids`,
+		oid,
+		Concat(
+			`LPAREN WSP oidlist WSP RPAREN`,
+			lparen,
+			wsp,
+			Concat(
+				`oidlist`,
+				oid,
+				Repeat0Inf(`*(WSP DOLLAR WSP oid)`, Concat(
+					`WSP DOLLAR WSP oid`,
+					wsp,
+					Rune(`DOLLAR`, '$'),
+					wsp,
+					oid,
+				)),
+			),
+			wsp,
+			rparen,
+		),
+	)(s)
+}
+
+func oid(s []rune) Alternatives {
+	return Alts(`oid`, descr, numericoid)(s)
+}
+
+// descr = keystring
+func descr(s []rune) Alternatives {
+	return Concat(
+		`descr`,
+		alpha,
+		Repeat0Inf(`*keychar`, Alts(
+			`keychar`,
+			alpha,
+			digit,
+			hyphen,
+		)),
+	)(s)
+}
+
+func numericoid(s []rune) Alternatives {
+	return Concat(
+		`numericoid`,
+		number,
+		Repeat1Inf(`1*(DOT number)`, Concat(
+			`DOT number`,
+			Rune(`DOT`, '.'),
+			number,
+		)),
+	)(s)
+}
+
+func noidlen(s []rune) Alternatives {
+	return Concat(
+		`noidlen`,
+		numericoid,
+		Optional(`[LCURLY len RCURLY]`, Concat(
+			`LCURLY len RCURLY`,
+			Rune(`LCURLY`, '{'),
+			number,
+			Rune(`RCURLY`, '}'),
+		)),
+	)(s)
+}
+
+func number(s []rune) Alternatives {
+	return Alts(
+		`number`,
+		digit,
+		Concat(
+			`LDIGIT 1*DIGIT`,
+			Range(`LDIGIT`, '1', '9'),
+			Repeat1Inf(`1*DIGIT`, digit),
+		),
+	)(s)
+}
+
+// SP = 1*SPACE
+func sp(s []rune) Alternatives {
+	return Repeat1Inf(`SP`, space)(s)
+}
+
+// WSP = 0*SPACE
+func wsp(s []rune) Alternatives {
+	return Repeat0Inf(`WSP`, space)(s)
+}
+
+var (
+	alpha = Alts(
+		`ALPHA`,
+		Range(`%x41-5A`, 65, 90),  // 65-90
+		Range(`%x61-7A`, 97, 122), // 97-122
+	)
+	digit  = Range(`DIGIT`, 48, 57) // 48-57
+	space  = Rune(`SPACE`, ' ')
+	hyphen = Rune(`HYPHEN`, '-')
+	squote = Rune(`SQUOTE`, '\'')
+	esc    = Rune(`ESC`, '\\')
+	lparen = Rune(`LPAREN`, '(')
+	rparen = Rune(`RPAREN`, ')')
+)
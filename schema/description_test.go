@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"testing"
+
+	. "github.com/elimity-com/abnf/operators"
+)
+
+func TestQDString(t *testing.T) {
+	for _, v := range []string{`'a'`, `'O\27Reilly'`, `'a\5Cb'`, `'a\5cb'`} {
+		if n, _ := parse(qdstring, `qdstring`, v); n == nil {
+			t.Errorf("could not parse qdstring: %s", v)
+		}
+	}
+	for _, v := range []string{`''`, `'a'b'`, `'a\b'`, `'a`} {
+		if n, _ := parse(qdstring, `qdstring`, v); n != nil {
+			t.Errorf("value found for %s", v)
+		}
+	}
+}
+
+func TestOIDs(t *testing.T) {
+	for _, v := range []string{`cn`, `2.5.4.3`, `( sn $ cn )`, `(sn$cn$2.5.4.3)`} {
+		if n, _ := parse(oids, `oids`, v); n == nil {
+			t.Errorf("could not parse oids: %s", v)
+		}
+	}
+	for _, v := range []string{`-cn`, `2.05.4`, `2`, `( sn cn )`, `( )`} {
+		if n, _ := parse(oids, `oids`, v); n != nil {
+			t.Errorf("value found for %s", v)
+		}
+	}
+}
+
+func TestDescriptionExamples(t *testing.T) {
+	for _, test := range []struct {
+		rule Operator
+		name string
+		str  string
+	}{
+		// RFC 4512: 4.1.2. Attribute Types
+		{attributeTypeDescription, `AttributeTypeDescription`, `( 2.5.4.0 NAME 'objectClass' EQUALITY objectIdentifierMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )`},
+		{attributeTypeDescription, `AttributeTypeDescription`, `( 2.5.4.41 NAME 'name' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{32768} )`},
+		{attributeTypeDescription, `AttributeTypeDescription`, `( 2.5.18.1 NAME 'createTimestamp' EQUALITY generalizedTimeMatch ORDERING generalizedTimeOrderingMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.24 SINGLE-VALUE NO-USER-MODIFICATION USAGE directoryOperation )`},
+		// RFC 4512: 4.1.1. Object Class Definitions
+		{objectClassDescription, `ObjectClassDescription`, `( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )`},
+		{objectClassDescription, `ObjectClassDescription`, `( 1.3.6.1.4.1.1466.101.120.111 NAME 'extensibleObject' SUP top AUXILIARY )`},
+		// RFC 4512: 4.1.3. Matching Rules
+		{matchingRuleDescription, `MatchingRuleDescription`, `( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`},
+		// RFC 4512: 4.1.5. LDAP Syntaxes
+		{ldapSyntaxDescription, `SyntaxDescription`, `( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )`},
+		{ldapSyntaxDescription, `SyntaxDescription`, `( 1.3.6.1.4.1.1466.115.121.1.5 DESC 'Binary' X-NOT-HUMAN-READABLE 'TRUE' )`},
+	} {
+		if _, err := parse(test.rule, test.name, test.str); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	. "github.com/elimity-com/abnf/operators"
+	"github.com/elimity-com/ldif"
+)
+
+// ParseAttributeType parses an AttributeTypeDescription, as in
+// "( 2.5.4.41 NAME 'name' EQUALITY caseIgnoreMatch )".
+func ParseAttributeType(s string) (*AttributeType, error) {
+	n, err := parse(attributeTypeDescription, `AttributeTypeDescription`, s)
+	if err != nil {
+		return nil, err
+	}
+	a := &AttributeType{
+		OID:         n.GetImmediateSubNode(`numericoid`).String(),
+		Names:       names(n),
+		Description: description(n),
+		Obsolete:    n.GetSubNode(`SP "OBSOLETE"`) != nil,
+		Superior:    oidOf(n, `SP "SUP" SP oid`),
+		Equality:    oidOf(n, `SP "EQUALITY" SP oid`),
+		Ordering:    oidOf(n, `SP "ORDERING" SP oid`),
+		Substring:   oidOf(n, `SP "SUBSTR" SP oid`),
+
+		SingleValue:        n.GetSubNode(`SP "SINGLE-VALUE"`) != nil,
+		Collective:         n.GetSubNode(`SP "COLLECTIVE"`) != nil,
+		NoUserModification: n.GetSubNode(`SP "NO-USER-MODIFICATION"`) != nil,
+		Extensions:         extensionsOf(n),
+	}
+	if syntax := n.GetSubNode(`SP "SYNTAX" SP noidlen`); syntax != nil {
+		a.Syntax = syntax.GetImmediateSubNode(`noidlen`).String()
+	}
+	if u := n.GetSubNode(`usage`); u != nil {
+		for _, usage := range []Usage{UserApplications, DirectoryOperation, DistributedOperation, DSAOperation} {
+			if strings.EqualFold(u.String(), usage.String()) {
+				a.Usage = usage
+			}
+		}
+	}
+	return a, nil
+}
+
+// ParseObjectClass parses an ObjectClassDescription, as in
+// "( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) )".
+func ParseObjectClass(s string) (*ObjectClass, error) {
+	n, err := parse(objectClassDescription, `ObjectClassDescription`, s)
+	if err != nil {
+		return nil, err
+	}
+	c := &ObjectClass{
+		OID:         n.GetImmediateSubNode(`numericoid`).String(),
+		Names:       names(n),
+		Description: description(n),
+		Obsolete:    n.GetSubNode(`SP "OBSOLETE"`) != nil,
+		Superiors:   oidsOf(n, `SP "SUP" SP oids`),
+		Must:        oidsOf(n, `SP "MUST" SP oids`),
+		May:         oidsOf(n, `SP "MAY" SP oids`),
+		Extensions:  extensionsOf(n),
+	}
+	if k := n.GetSubNode(`kind`); k != nil {
+		for _, kind := range []Kind{Structural, Abstract, Auxiliary} {
+			if strings.EqualFold(k.String(), kind.String()) {
+				c.Kind = kind
+			}
+		}
+	}
+	return c, nil
+}
+
+// ParseMatchingRule parses a MatchingRuleDescription, as in
+// "( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )".
+func ParseMatchingRule(s string) (*MatchingRule, error) {
+	n, err := parse(matchingRuleDescription, `MatchingRuleDescription`, s)
+	if err != nil {
+		return nil, err
+	}
+	return &MatchingRule{
+		OID:         n.GetImmediateSubNode(`numericoid`).String(),
+		Names:       names(n),
+		Description: description(n),
+		Obsolete:    n.GetSubNode(`SP "OBSOLETE"`) != nil,
+		Syntax:      n.GetSubNode(`SP "SYNTAX" SP numericoid`).GetImmediateSubNode(`numericoid`).String(),
+		Extensions:  extensionsOf(n),
+	}, nil
+}
+
+// ParseLDAPSyntax parses a SyntaxDescription, as in
+// "( 1.3.6.1.4.1.1466.115.121.1.27 DESC 'INTEGER' )".
+func ParseLDAPSyntax(s string) (*LDAPSyntax, error) {
+	n, err := parse(ldapSyntaxDescription, `SyntaxDescription`, s)
+	if err != nil {
+		return nil, err
+	}
+	return &LDAPSyntax{
+		OID:         n.GetImmediateSubNode(`numericoid`).String(),
+		Description: description(n),
+		Extensions:  extensionsOf(n),
+	}, nil
+}
+
+// parse returns the node of the rule that matches the whole string.
+func parse(rule Operator, name, s string) (*Node, error) {
+	input := []rune(s)
+	for _, a := range rule(input) {
+		if len(a.Value) == len(input) {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s: %q", name, s)
+}
+
+func names(n *Node) []string {
+	qdescrs := n.GetSubNode(`SP "NAME" SP qdescrs`)
+	if qdescrs == nil {
+		return nil
+	}
+	var names []string
+	for _, d := range qdescrs.GetSubNodes(`descr`) {
+		names = append(names, d.String())
+	}
+	return names
+}
+
+func description(n *Node) string {
+	desc := n.GetSubNode(`SP "DESC" SP qdstring`)
+	if desc == nil {
+		return ""
+	}
+	return unescape(desc.GetImmediateSubNode(`qdstring`))
+}
+
+// oidOf returns the oid of the node with the given key, if any.
+func oidOf(n *Node, key string) string {
+	if n = n.GetSubNode(key); n == nil {
+		return ""
+	}
+	return n.GetImmediateSubNode(`oid`).String()
+}
+
+// oidsOf returns the oids of the node with the given key, if any.
+func oidsOf(n *Node, key string) []string {
+	if n = n.GetSubNode(key); n == nil {
+		return nil
+	}
+	var oids []string
+	for _, oid := range n.GetSubNodes(`oid`) {
+		oids = append(oids, oid.String())
+	}
+	return oids
+}
+
+func extensionsOf(n *Node) map[string][]string {
+	extensions := n.GetSubNodes(`SP xstring SP qdstrings`)
+	if len(extensions) == 0 {
+		return nil
+	}
+	m := make(map[string][]string, len(extensions))
+	for _, e := range extensions {
+		name := e.GetImmediateSubNode(`xstring`).String()
+		for _, value := range e.GetSubNodes(`qdstring`) {
+			m[name] = append(m[name], unescape(value))
+		}
+	}
+	return m
+}
+
+// unescape returns the value of a qdstring node, of which "\27" and "\5C" are
+// replaced by QUOTE and "\".
+func unescape(qdstring *Node) string {
+	var b strings.Builder
+	for _, c := range qdstring.GetImmediateSubNode(`dstring`).Children {
+		switch c.Children[0].Key {
+		case `QQ`:
+			b.WriteByte('\'')
+		case `QS`:
+			b.WriteByte('\\')
+		default:
+			b.WriteString(c.String())
+		}
+	}
+	return b.String()
+}
+
+// index matches the "{n}" prefix that OpenLDAP adds to the values of ordered
+// attributes, as in "{0}( 2.5.4.41 NAME 'name' )".
+var index = regexp.MustCompile(`^{\d+}`)
+
+// Load adds the definitions of the schema entries and add records, of which the
+// attributeTypes, objectClasses, matchingRules and ldapSyntaxes attributes (or
+// their OpenLDAP olc* equivalents) are parsed. Other attributes and records are
+// ignored.
+func (s *Schema) Load(records []ldif.Record) error {
+	for _, r := range records {
+		var dn string
+		var attributes []ldif.Attribute
+		switch r := r.(type) {
+		case *ldif.Entry:
+			dn, attributes = r.DN, r.Attributes
+		case *ldif.AddRecord:
+			dn, attributes = r.DN, r.Attributes
+		default:
+			continue
+		}
+		for _, a := range attributes {
+			if err := s.add(a.Type, index.ReplaceAllString(a.Value, "")); err != nil {
+				return fmt.Errorf("%s: %s: %v", dn, a.Type, err)
+			}
+		}
+	}
+	return nil
+}
+
+// add parses and adds the value of the schema attribute with the given type.
+func (s *Schema) add(typ, value string) error {
+	switch strings.ToLower(typ) {
+	case "attributetypes", "olcattributetypes":
+		a, err := ParseAttributeType(value)
+		if err != nil {
+			return err
+		}
+		return s.AddAttributeType(a)
+	case "objectclasses", "olcobjectclasses":
+		c, err := ParseObjectClass(value)
+		if err != nil {
+			return err
+		}
+		return s.AddObjectClass(c)
+	case "matchingrules":
+		r, err := ParseMatchingRule(value)
+		if err != nil {
+			return err
+		}
+		return s.AddMatchingRule(r)
+	case "ldapsyntaxes", "olcldapsyntaxes":
+		syntax, err := ParseLDAPSyntax(value)
+		if err != nil {
+			return err
+		}
+		return s.AddLDAPSyntax(syntax)
+	}
+	return nil
+}
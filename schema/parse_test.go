@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/elimity-com/ldif"
+)
+
+func TestParseAttributeType(t *testing.T) {
+	a, err := ParseAttributeType(`( 2.5.4.3 NAME ( 'cn' 'commonName' ) DESC 'O\27Reilly \5C common name' SUP name SINGLE-VALUE USAGE dSAOperation X-ORIGIN ( 'RFC 4519' 'RFC 2256' ) )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &AttributeType{
+		OID:         "2.5.4.3",
+		Names:       []string{"cn", "commonName"},
+		Description: `O'Reilly \ common name`,
+		Superior:    "name",
+		SingleValue: true,
+		Usage:       DSAOperation,
+		Extensions:  map[string][]string{"X-ORIGIN": {"RFC 4519", "RFC 2256"}},
+	}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("expected %+v, got %+v", expected, a)
+	}
+
+	a, err = ParseAttributeType(`( 2.5.18.1 NAME 'createTimestamp' EQUALITY generalizedTimeMatch ORDERING generalizedTimeOrderingMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.24{64} OBSOLETE )`)
+	if err == nil {
+		t.Errorf("expected an error for OBSOLETE out of order, got %+v", a)
+	}
+	a, err = ParseAttributeType(`( 2.5.18.1 NAME 'createTimestamp' OBSOLETE EQUALITY generalizedTimeMatch ORDERING generalizedTimeOrderingMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.24{64} )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Obsolete || a.Equality != "generalizedTimeMatch" || a.Ordering != "generalizedTimeOrderingMatch" || a.Syntax != "1.3.6.1.4.1.1466.115.121.1.24{64}" {
+		t.Errorf("unexpected attribute type: %+v", a)
+	}
+}
+
+func TestParseObjectClass(t *testing.T) {
+	c, err := ParseObjectClass(`( 2.5.6.7 NAME 'organizationalPerson' SUP person STRUCTURAL MAY ( title $ x121Address $ 2.5.4.11 ) )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ObjectClass{
+		OID:       "2.5.6.7",
+		Names:     []string{"organizationalPerson"},
+		Superiors: []string{"person"},
+		Kind:      Structural,
+		May:       []string{"title", "x121Address", "2.5.4.11"},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("expected %+v, got %+v", expected, c)
+	}
+
+	c, err = ParseObjectClass(`( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Kind != Abstract || !reflect.DeepEqual(c.Must, []string{"objectClass"}) {
+		t.Errorf("unexpected object class: %+v", c)
+	}
+
+	if _, err := ParseObjectClass(`( person )`); err == nil {
+		t.Error("expected an error for a missing numericoid")
+	}
+}
+
+func TestParseMatchingRuleAndSyntax(t *testing.T) {
+	r, err := ParseMatchingRule(`( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name() != "caseIgnoreMatch" || r.Syntax != "1.3.6.1.4.1.1466.115.121.1.15" {
+		t.Errorf("unexpected matching rule: %+v", r)
+	}
+	if _, err := ParseMatchingRule(`( 2.5.13.2 NAME 'caseIgnoreMatch' )`); err == nil {
+		t.Error("expected an error for a missing syntax")
+	}
+
+	syntax, err := ParseLDAPSyntax(`( 1.3.6.1.4.1.1466.115.121.1.5 DESC 'Binary' X-NOT-HUMAN-READABLE 'TRUE' )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syntax.Description != "Binary" || !reflect.DeepEqual(syntax.Extensions["X-NOT-HUMAN-READABLE"], []string{"TRUE"}) {
+		t.Errorf("unexpected ldap syntax: %+v", syntax)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/core.ldif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ldif.Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New()
+	if err := s.Load(records); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load([]ldif.Record{&ldif.Entry{
+		DN: "cn=Subschema",
+		Attributes: []ldif.Attribute{
+			{Type: "ldapSyntaxes", Value: "( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )"},
+			{Type: "matchingRules", Value: "( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )"},
+		},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if a, ok := s.AttributeType("surname"); !ok || a.Superior != "name" {
+		t.Errorf("unexpected attribute type: %+v", a)
+	}
+	if a, ok := s.AttributeType("name"); !ok || a.Syntax != "1.3.6.1.4.1.1466.115.121.1.15{32768}" {
+		t.Errorf("unexpected attribute type: %+v", a)
+	}
+	if _, ok := s.LDAPSyntax("1.3.6.1.4.1.1466.115.121.1.15{32768}"); !ok {
+		t.Error("expected the directory string syntax")
+	}
+	if _, ok := s.MatchingRule("caseignorematch"); !ok {
+		t.Error("expected the caseIgnoreMatch matching rule")
+	}
+
+	records, err = ldif.Parse(`version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: top
+objectclass: person
+cn: Barbara Jensen
+sn: Jensen
+description: A big sailing fan.
+createTimestamp: 20200101120000Z
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := s.Validate(records); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	if err := s.Load([]ldif.Record{&ldif.Entry{
+		DN:         "cn=Subschema",
+		Attributes: []ldif.Attribute{{Type: "objectClasses", Value: "( 2.5.6.6 NAME 'person' )"}},
+	}}); err == nil {
+		t.Error("expected a duplicate object class error")
+	}
+	if err := s.Load([]ldif.Record{&ldif.Entry{
+		DN:         "cn=Subschema",
+		Attributes: []ldif.Attribute{{Type: "attributeTypes", Value: "( 2.5.4.3 NAME cn )"}},
+	}}); err == nil {
+		t.Error("expected an invalid attribute type error")
+	}
+}
@@ -0,0 +1,274 @@
+// Package schema implements LDAP schema definitions, as defined in RFC 4512,
+// and the validation of LDIF records against them.
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of an object class.
+type Kind int
+
+const (
+	Structural Kind = iota
+	Abstract
+	Auxiliary
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Structural:
+		return "STRUCTURAL"
+	case Abstract:
+		return "ABSTRACT"
+	case Auxiliary:
+		return "AUXILIARY"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Usage is the usage of an attribute type.
+type Usage int
+
+const (
+	UserApplications Usage = iota
+	DirectoryOperation
+	DistributedOperation
+	DSAOperation
+)
+
+func (u Usage) String() string {
+	switch u {
+	case UserApplications:
+		return "userApplications"
+	case DirectoryOperation:
+		return "directoryOperation"
+	case DistributedOperation:
+		return "distributedOperation"
+	case DSAOperation:
+		return "dSAOperation"
+	default:
+		return fmt.Sprintf("Usage(%d)", int(u))
+	}
+}
+
+// ObjectClass is an object class definition, described by an
+// ObjectClassDescription.
+type ObjectClass struct {
+	OID         string
+	Names       []string
+	Description string
+	Obsolete    bool
+	// Superiors are the names or OIDs of the superclasses.
+	Superiors []string
+	Kind      Kind
+	// Must and May are the names or OIDs of the required and allowed attribute
+	// types.
+	Must, May []string
+	// Extensions maps the names of the extensions (e.g. "X-ORIGIN") to their
+	// values.
+	Extensions map[string][]string
+}
+
+// AttributeType is an attribute type definition, described by an
+// AttributeTypeDescription.
+type AttributeType struct {
+	OID         string
+	Names       []string
+	Description string
+	Obsolete    bool
+	// Superior is the name or OID of the supertype, if any.
+	Superior string
+	// Equality, Ordering and Substring are the names or OIDs of the matching
+	// rules of the attribute type.
+	Equality, Ordering, Substring string
+	// Syntax is the OID of the syntax of the attribute type, including its
+	// length bound (e.g. "1.3.6.1.4.1.1466.115.121.1.15{32768}").
+	Syntax             string
+	SingleValue        bool
+	Collective         bool
+	NoUserModification bool
+	Usage              Usage
+	Extensions         map[string][]string
+}
+
+// MatchingRule is a matching rule definition, described by a
+// MatchingRuleDescription.
+type MatchingRule struct {
+	OID         string
+	Names       []string
+	Description string
+	Obsolete    bool
+	// Syntax is the OID of the assertion syntax.
+	Syntax     string
+	Extensions map[string][]string
+}
+
+// LDAPSyntax is an LDAP syntax definition, described by a SyntaxDescription.
+type LDAPSyntax struct {
+	OID         string
+	Description string
+	Extensions  map[string][]string
+}
+
+// Name returns the first name of the object class, or its OID if it has none.
+func (c *ObjectClass) Name() string {
+	if len(c.Names) != 0 {
+		return c.Names[0]
+	}
+	return c.OID
+}
+
+// Name returns the first name of the attribute type, or its OID if it has none.
+func (a *AttributeType) Name() string {
+	if len(a.Names) != 0 {
+		return a.Names[0]
+	}
+	return a.OID
+}
+
+// Name returns the first name of the matching rule, or its OID if it has none.
+func (r *MatchingRule) Name() string {
+	if len(r.Names) != 0 {
+		return r.Names[0]
+	}
+	return r.OID
+}
+
+// Schema is a set of object classes, attribute types, matching rules and LDAP
+// syntaxes. They are looked up by any of their names, case-insensitively, or by
+// their OID.
+type Schema struct {
+	objectClasses  map[string]*ObjectClass
+	attributeTypes map[string]*AttributeType
+	matchingRules  map[string]*MatchingRule
+	ldapSyntaxes   map[string]*LDAPSyntax
+}
+
+// New returns an empty schema.
+func New() *Schema {
+	return &Schema{
+		objectClasses:  make(map[string]*ObjectClass),
+		attributeTypes: make(map[string]*AttributeType),
+		matchingRules:  make(map[string]*MatchingRule),
+		ldapSyntaxes:   make(map[string]*LDAPSyntax),
+	}
+}
+
+// AddObjectClass adds the object class, it fails if one of its names or its OID
+// is already in use by another object class.
+func (s *Schema) AddObjectClass(c *ObjectClass) error {
+	keys := append([]string{c.OID}, c.Names...)
+	for _, k := range keys {
+		if _, ok := s.objectClasses[strings.ToLower(k)]; ok {
+			return fmt.Errorf("duplicate object class: %s", k)
+		}
+	}
+	for _, k := range keys {
+		s.objectClasses[strings.ToLower(k)] = c
+	}
+	return nil
+}
+
+// AddAttributeType adds the attribute type, it fails if one of its names or its
+// OID is already in use by another attribute type.
+func (s *Schema) AddAttributeType(a *AttributeType) error {
+	keys := append([]string{a.OID}, a.Names...)
+	for _, k := range keys {
+		if _, ok := s.attributeTypes[strings.ToLower(k)]; ok {
+			return fmt.Errorf("duplicate attribute type: %s", k)
+		}
+	}
+	for _, k := range keys {
+		s.attributeTypes[strings.ToLower(k)] = a
+	}
+	return nil
+}
+
+// AddMatchingRule adds the matching rule, it fails if one of its names or its OID
+// is already in use by another matching rule.
+func (s *Schema) AddMatchingRule(r *MatchingRule) error {
+	keys := append([]string{r.OID}, r.Names...)
+	for _, k := range keys {
+		if _, ok := s.matchingRules[strings.ToLower(k)]; ok {
+			return fmt.Errorf("duplicate matching rule: %s", k)
+		}
+	}
+	for _, k := range keys {
+		s.matchingRules[strings.ToLower(k)] = r
+	}
+	return nil
+}
+
+// AddLDAPSyntax adds the LDAP syntax, it fails if its OID is already in use by
+// another LDAP syntax.
+func (s *Schema) AddLDAPSyntax(syntax *LDAPSyntax) error {
+	if _, ok := s.ldapSyntaxes[syntax.OID]; ok {
+		return fmt.Errorf("duplicate ldap syntax: %s", syntax.OID)
+	}
+	s.ldapSyntaxes[syntax.OID] = syntax
+	return nil
+}
+
+// ObjectClass returns the object class with the given name or OID.
+func (s *Schema) ObjectClass(name string) (*ObjectClass, bool) {
+	c, ok := s.objectClasses[strings.ToLower(name)]
+	return c, ok
+}
+
+// AttributeType returns the attribute type with the given name or OID. Options
+// of an attribute description are ignored. (e.g. "cn;lang-en" returns "cn")
+func (s *Schema) AttributeType(name string) (*AttributeType, bool) {
+	if i := strings.IndexByte(name, ';'); i >= 0 {
+		name = name[:i]
+	}
+	a, ok := s.attributeTypes[strings.ToLower(name)]
+	return a, ok
+}
+
+// MatchingRule returns the matching rule with the given name or OID.
+func (s *Schema) MatchingRule(name string) (*MatchingRule, bool) {
+	r, ok := s.matchingRules[strings.ToLower(name)]
+	return r, ok
+}
+
+// LDAPSyntax returns the LDAP syntax with the given OID, a length bound is
+// ignored. (e.g. "1.3.6.1.4.1.1466.115.121.1.15{32768}")
+func (s *Schema) LDAPSyntax(oid string) (*LDAPSyntax, bool) {
+	if i := strings.IndexByte(oid, '{'); i >= 0 {
+		oid = oid[:i]
+	}
+	syntax, ok := s.ldapSyntaxes[oid]
+	return syntax, ok
+}
+
+// superclasses returns the object class and all of its (indirect) superclasses.
+// Unknown superclasses are ignored.
+func (s *Schema) superclasses(c *ObjectClass) []*ObjectClass {
+	classes := []*ObjectClass{c}
+	seen := map[*ObjectClass]bool{c: true}
+	for i := 0; i < len(classes); i++ {
+		for _, name := range classes[i].Superiors {
+			if sup, ok := s.ObjectClass(name); ok && !seen[sup] {
+				seen[sup] = true
+				classes = append(classes, sup)
+			}
+		}
+	}
+	return classes
+}
+
+// isSubtype checks whether the attribute type is equal to or a (indirect)
+// subtype of the given supertype.
+func (s *Schema) isSubtype(a, super *AttributeType) bool {
+	for seen := make(map[*AttributeType]bool); a != nil && !seen[a]; {
+		if a == super {
+			return true
+		}
+		seen[a] = true
+		a, _ = s.AttributeType(a.Superior)
+	}
+	return false
+}
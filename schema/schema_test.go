@@ -0,0 +1,78 @@
+package schema
+
+import "testing"
+
+// newTestSchema returns a small subset of the schemas of RFC 4519 and RFC 4512.
+func newTestSchema(t *testing.T) *Schema {
+	s := New()
+	for _, c := range []*ObjectClass{
+		{OID: "2.5.6.0", Names: []string{"top"}, Kind: Abstract, Must: []string{"objectClass"}},
+		{OID: "2.5.6.6", Names: []string{"person"}, Superiors: []string{"top"}, Must: []string{"sn", "cn"}, May: []string{"userPassword", "telephoneNumber", "seeAlso", "description"}},
+		{OID: "2.5.6.7", Names: []string{"organizationalPerson"}, Superiors: []string{"person"}, May: []string{"title", "ou"}},
+		{OID: "2.5.6.5", Names: []string{"organizationalUnit"}, Superiors: []string{"top"}, Must: []string{"ou"}, May: []string{"description"}},
+		{OID: "1.3.6.1.1.3.1", Names: []string{"uidObject"}, Superiors: []string{"top"}, Kind: Auxiliary, Must: []string{"uid"}},
+		{OID: "1.3.6.1.4.1.1466.101.120.111", Names: []string{"extensibleObject"}, Superiors: []string{"top"}, Kind: Auxiliary},
+	} {
+		if err := s.AddObjectClass(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, a := range []*AttributeType{
+		{OID: "2.5.4.0", Names: []string{"objectClass"}},
+		{OID: "2.5.4.41", Names: []string{"name"}},
+		{OID: "2.5.4.3", Names: []string{"cn", "commonName"}, Superior: "name"},
+		{OID: "2.5.4.4", Names: []string{"sn", "surname"}, Superior: "name"},
+		{OID: "2.5.4.11", Names: []string{"ou", "organizationalUnitName"}, Superior: "name"},
+		{OID: "2.5.4.12", Names: []string{"title"}, Superior: "name"},
+		{OID: "2.5.4.13", Names: []string{"description"}},
+		{OID: "2.5.4.20", Names: []string{"telephoneNumber"}, Syntax: TelephoneNumberSyntax},
+		{OID: "2.5.4.34", Names: []string{"seeAlso"}},
+		{OID: "2.5.4.35", Names: []string{"userPassword"}},
+		{OID: "0.9.2342.19200300.100.1.1", Names: []string{"uid", "userid"}},
+		{OID: "2.16.840.1.113730.3.1.39", Names: []string{"preferredLanguage"}, SingleValue: true},
+	} {
+		if err := s.AddAttributeType(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestSchemaLookup(t *testing.T) {
+	s := newTestSchema(t)
+	for _, name := range []string{"person", "PERSON", "2.5.6.6"} {
+		if c, ok := s.ObjectClass(name); !ok || c.OID != "2.5.6.6" {
+			t.Errorf("%s: expected person, got %v", name, c)
+		}
+	}
+	for _, name := range []string{"cn", "commonName", "CN;lang-en", "2.5.4.3"} {
+		if a, ok := s.AttributeType(name); !ok || a.OID != "2.5.4.3" {
+			t.Errorf("%s: expected cn, got %v", name, a)
+		}
+	}
+	if _, ok := s.AttributeType("unknown"); ok {
+		t.Error("expected unknown attribute type")
+	}
+}
+
+func TestSchemaDuplicate(t *testing.T) {
+	s := newTestSchema(t)
+	if err := s.AddObjectClass(&ObjectClass{OID: "1.2.3", Names: []string{"Person"}}); err == nil {
+		t.Error("expected a duplicate object class error")
+	}
+	if err := s.AddAttributeType(&AttributeType{OID: "2.5.4.3"}); err == nil {
+		t.Error("expected a duplicate attribute type error")
+	}
+}
+
+func TestIsSubtype(t *testing.T) {
+	s := newTestSchema(t)
+	cn, _ := s.AttributeType("cn")
+	name, _ := s.AttributeType("name")
+	if !s.isSubtype(cn, name) || !s.isSubtype(cn, cn) {
+		t.Error("expected cn to be a subtype of name")
+	}
+	if s.isSubtype(name, cn) {
+		t.Error("expected name not to be a subtype of cn")
+	}
+}
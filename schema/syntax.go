@@ -0,0 +1,218 @@
+package schema
+
+// RFC 4517: 3.3. Syntax Definitions
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+	"github.com/elimity-com/ldif/dn3"
+)
+
+// The OIDs of the LDAP syntaxes of RFC 4517 that values are validated against.
+const (
+	BooleanSyntax         = "1.3.6.1.4.1.1466.115.121.1.7"
+	DNSyntax              = "1.3.6.1.4.1.1466.115.121.1.12"
+	GeneralizedTimeSyntax = "1.3.6.1.4.1.1466.115.121.1.24"
+	IA5StringSyntax       = "1.3.6.1.4.1.1466.115.121.1.26"
+	IntegerSyntax         = "1.3.6.1.4.1.1466.115.121.1.27"
+	NumericStringSyntax   = "1.3.6.1.4.1.1466.115.121.1.36"
+	OIDSyntax             = "1.3.6.1.4.1.1466.115.121.1.38"
+	PostalAddressSyntax   = "1.3.6.1.4.1.1466.115.121.1.41"
+	PrintableStringSyntax = "1.3.6.1.4.1.1466.115.121.1.44"
+	TelephoneNumberSyntax = "1.3.6.1.4.1.1466.115.121.1.50"
+)
+
+var syntaxRules = map[string]struct {
+	name string
+	rule Operator
+}{
+	BooleanSyntax:         {`Boolean`, boolean},
+	GeneralizedTimeSyntax: {`GeneralizedTime`, generalizedTime},
+	IA5StringSyntax:       {`IA5String`, ia5String},
+	IntegerSyntax:         {`Integer`, integer},
+	NumericStringSyntax:   {`NumericString`, numericString},
+	OIDSyntax:             {`OID`, oid},
+	PostalAddressSyntax:   {`PostalAddress`, postalAddress},
+	PrintableStringSyntax: {`PrintableString`, printableString},
+	// TelephoneNumber = PrintableString
+	TelephoneNumberSyntax: {`TelephoneNumber`, printableString},
+}
+
+// ValidateSyntax checks whether the value conforms to the LDAP syntax with the
+// given OID, a length bound is ignored. Values of other syntaxes than the ones
+// listed above are always valid.
+func ValidateSyntax(syntax, value string) error {
+	if i := strings.IndexByte(syntax, '{'); i >= 0 {
+		syntax = syntax[:i]
+	}
+	if syntax == DNSyntax {
+		_, err := dn3.ParseDN(value)
+		return err
+	}
+	r, ok := syntaxRules[syntax]
+	if !ok {
+		return nil
+	}
+	_, err := parse(r.rule, r.name, value)
+	return err
+}
+
+// syntax returns the syntax of the attribute type, which is inherited from its
+// supertype if it has none.
+func (s *Schema) syntax(a *AttributeType) string {
+	for seen := make(map[*AttributeType]bool); a != nil && !seen[a]; {
+		if a.Syntax != "" {
+			return a.Syntax
+		}
+		seen[a] = true
+		a, _ = s.AttributeType(a.Superior)
+	}
+	return ""
+}
+
+// OpenLDAP and most other servers only accept upper case values, contrary to
+// the case-insensitive strings of ABNF.
+func boolean(s []rune) Alternatives {
+	return Alts(
+		`Boolean`,
+		StringCS(`TRUE`, "TRUE"),
+		StringCS(`FALSE`, "FALSE"),
+	)(s)
+}
+
+func integer(s []rune) Alternatives {
+	return Alts(
+		`Integer`,
+		Concat(
+			`HYPHEN LDIGIT *DIGIT`,
+			hyphen,
+			Range(`LDIGIT`, '1', '9'),
+			Repeat0Inf(`*DIGIT`, digit),
+		),
+		number,
+	)(s)
+}
+
+func generalizedTime(s []rune) Alternatives {
+	return Concat(
+		`GeneralizedTime`,
+		RepeatN(`century`, 2, digit),
+		RepeatN(`year`, 2, digit),
+		month,
+		day,
+		hour,
+		Optional(`[minute [second / leap-second]]`, Concat(
+			`minute [second / leap-second]`,
+			minute,
+			Optional(`[second / leap-second]`, Alts(
+				`second / leap-second`,
+				minute,
+				Concat(`leap-second`, Rune(`6`, '6'), Rune(`0`, '0')),
+			)),
+		)),
+		Optional(`[fraction]`, Concat(
+			`fraction`,
+			Alts(`DOT / COMMA`, Rune(`DOT`, '.'), Rune(`COMMA`, ',')),
+			Repeat1Inf(`1*DIGIT`, digit),
+		)),
+		Alts(
+			`g-time-zone`,
+			Rune(`Z`, 'Z'),
+			Concat(
+				`g-differential`,
+				Alts(`MINUS / PLUS`, hyphen, Rune(`PLUS`, '+')),
+				hour,
+				Optional(`[minute]`, minute),
+			),
+		),
+	)(s)
+}
+
+func month(s []rune) Alternatives {
+	return Alts(
+		`month`,
+		Concat(`01-09`, Rune(`0`, '0'), Range(`1-9`, '1', '9')),
+		Concat(`10-12`, Rune(`1`, '1'), Range(`0-2`, '0', '2')),
+	)(s)
+}
+
+func day(s []rune) Alternatives {
+	return Alts(
+		`day`,
+		Concat(`01-09`, Rune(`0`, '0'), Range(`1-9`, '1', '9')),
+		Concat(`10-29`, Range(`1-2`, '1', '2'), digit),
+		Concat(`30-31`, Rune(`3`, '3'), Range(`0-1`, '0', '1')),
+	)(s)
+}
+
+func hour(s []rune) Alternatives {
+	return Alts(
+		`hour`,
+		Concat(`00-19`, Range(`0-1`, '0', '1'), digit),
+		Concat(`20-23`, Rune(`2`, '2'), Range(`0-3`, '0', '3')),
+	)(s)
+}
+
+// minute is also used for second, they have the same range.
+func minute(s []rune) Alternatives {
+	return Concat(`minute`, Range(`0-5`, '0', '5'), digit)(s)
+}
+
+func ia5String(s []rune) Alternatives {
+	return Repeat0Inf(`IA5String`, Range(`%x00-7F`, 0x00, 0x7F))(s)
+}
+
+func numericString(s []rune) Alternatives {
+	return Repeat1Inf(`NumericString`, Alts(
+		`DIGIT / SPACE`,
+		digit,
+		space,
+	))(s)
+}
+
+func printableString(s []rune) Alternatives {
+	return Repeat1Inf(`PrintableString`, Alts(
+		`PrintableCharacter`,
+		alpha,
+		digit,
+		squote,
+		lparen,
+		rparen,
+		Rune(`PLUS`, '+'),
+		Rune(`COMMA`, ','),
+		hyphen,
+		Rune(`DOT`, '.'),
+		Rune(`EQUALS`, '='),
+		Rune(`SLASH`, '/'),
+		Rune(`COLON`, ':'),
+		Rune(`QUESTION`, '?'),
+		space,
+	))(s)
+}
+
+func postalAddress(s []rune) Alternatives {
+	return Concat(
+		`PostalAddress`,
+		line,
+		Repeat0Inf(`*(DOLLAR line)`, Concat(
+			`DOLLAR line`,
+			Rune(`DOLLAR`, '$'),
+			line,
+		)),
+	)(s)
+}
+
+// line is a line of a postal address, of which "$" and "\" are escaped as "\24"
+// and "\5C".
+func line(s []rune) Alternatives {
+	return Repeat1Inf(`line`, Alts(
+		`line-char`,
+		Range(`%x00-23`, 0x00, 0x23),
+		Concat(`"\24"`, esc, Rune(`2`, '2'), Rune(`4`, '4')),
+		Range(`%x25-5B`, 0x25, 0x5B),
+		Concat(`"\5C"`, esc, Rune(`5`, '5'), Alts(`C`, Rune(`C`, 'C'), Rune(`c`, 'c'))),
+		Range(`%x5D-`, 0x5D, utf8.MaxRune),
+	))(s)
+}
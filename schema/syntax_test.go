@@ -0,0 +1,72 @@
+package schema
+
+import "testing"
+
+func TestValidateSyntax(t *testing.T) {
+	for _, test := range []struct {
+		syntax  string
+		valid   []string
+		invalid []string
+	}{
+		{BooleanSyntax, []string{"TRUE", "FALSE"}, []string{"true", "yes", ""}},
+		{IntegerSyntax, []string{"0", "42", "-1", "1234567890"}, []string{"-0", "007", "+1", "1.5", ""}},
+		{
+			GeneralizedTimeSyntax,
+			[]string{"199412161032Z", "199412160532-0500", "199412161032.5Z", "20200229235960Z", "2020022923+01", "20200229230000,123Z"},
+			[]string{"199412161032", "19941316103200Z", "19941232103200Z", "19941216243200Z", "199412161061Z", "1994121610Z0"},
+		},
+		{TelephoneNumberSyntax, []string{"+1 408 555 1212", "+1 (408) 555-1212"}, []string{"+1 408 555 1212 #1", "+1 408 555 1212 ext. 3 & 4", ""}},
+		{PrintableStringSyntax, []string{"Barbara Jensen", "O'Reilly, A.B.C.=/:?"}, []string{"Barbara_Jensen", "Lučić", ""}},
+		{IA5StringSyntax, []string{"bjensen@airius.com", ""}, []string{"Lučić"}},
+		{DNSyntax, []string{"cn=Barbara Jensen, dc=airius, dc=com", ""}, []string{"Barbara Jensen"}},
+		{OIDSyntax, []string{"1.3.6.1.4.1.1466.0", "cn"}, []string{"1", "1.03", "-cn", ""}},
+		{NumericStringSyntax, []string{"15 079 672 281"}, []string{"15-079", ""}},
+		{
+			PostalAddressSyntax,
+			[]string{"1234 Main St.$Anytown, CA 12345$USA", `\241,000,000 Sweepstakes$PO Box 1000000$Anytown, CA 12345$USA`, `a\5Cb`},
+			[]string{"$USA", "Main St.$$USA", `\$`, `a\b`, ""},
+		},
+	} {
+		for _, v := range test.valid {
+			if err := ValidateSyntax(test.syntax, v); err != nil {
+				t.Errorf("%s: %v", test.syntax, err)
+			}
+		}
+		for _, v := range test.invalid {
+			if err := ValidateSyntax(test.syntax, v); err == nil {
+				t.Errorf("%s: expected an error for %q", test.syntax, v)
+			}
+		}
+	}
+
+	// length bounds and unknown syntaxes are ignored
+	if err := ValidateSyntax(IntegerSyntax+"{2}", "123"); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateSyntax("1.3.6.1.4.1.1466.115.121.1.15", "anything"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSyntaxInheritance(t *testing.T) {
+	s := New()
+	for _, a := range []*AttributeType{
+		{OID: "2.5.4.41", Names: []string{"name"}, Syntax: PrintableStringSyntax + "{32768}"},
+		{OID: "2.5.4.3", Names: []string{"cn"}, Superior: "name"},
+		{OID: "2.5.4.4", Names: []string{"sn"}, Superior: "name", Syntax: IA5StringSyntax},
+	} {
+		if err := s.AddAttributeType(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, expected := range map[string]string{
+		"name": PrintableStringSyntax + "{32768}",
+		"cn":   PrintableStringSyntax + "{32768}",
+		"sn":   IA5StringSyntax,
+	} {
+		a, _ := s.AttributeType(name)
+		if syntax := s.syntax(a); syntax != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, syntax)
+		}
+	}
+}
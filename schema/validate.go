@@ -0,0 +1,200 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elimity-com/ldif"
+)
+
+// extensibleObject is the OID of the object class that allows all user
+// attributes, as defined in RFC 4512.
+const extensibleObject = "1.3.6.1.4.1.1466.101.120.111"
+
+// ValidationError describes why an entry does not conform to the schema.
+type ValidationError struct {
+	DN string
+	// Attribute is the attribute description the error refers to, if any.
+	Attribute string
+	Message   string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Attribute == "" {
+		return fmt.Sprintf("%s: %s", e.DN, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.DN, e.Attribute, e.Message)
+}
+
+// Validate validates the entries and add records against the schema, other
+// records are ignored. It returns the errors of all records, in order.
+func (s *Schema) Validate(records []ldif.Record) []*ValidationError {
+	var errs []*ValidationError
+	for _, r := range records {
+		switch r := r.(type) {
+		case *ldif.Entry:
+			errs = append(errs, s.ValidateEntry(r.DN, r.Attributes)...)
+		case *ldif.AddRecord:
+			errs = append(errs, s.ValidateEntry(r.DN, r.Attributes)...)
+		}
+	}
+	return errs
+}
+
+// ValidateEntry validates the attributes of the entry with the given
+// distinguished name against the schema:
+//   - all object classes and attribute types are defined,
+//   - the object classes have exactly one structural superclass chain,
+//   - all attributes required by the object classes (or their superclasses)
+//     are present and all other attributes are allowed by them,
+//   - single-valued attributes have at most one value,
+//   - the values conform to the syntaxes of their attribute types.
+func (s *Schema) ValidateEntry(dn string, attributes []ldif.Attribute) []*ValidationError {
+	var errs []*ValidationError
+	report := func(attribute, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{DN: dn, Attribute: attribute, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var classes []*ObjectClass
+	seen := make(map[*ObjectClass]bool)
+	found := false
+	for _, a := range attributes {
+		if !strings.EqualFold(a.Type, "objectClass") {
+			continue
+		}
+		found = true
+		c, ok := s.ObjectClass(strings.TrimSpace(a.Value))
+		if !ok {
+			report("", "undefined object class: %s", a.Value)
+			continue
+		}
+		for _, sup := range s.superclasses(c) {
+			if !seen[sup] {
+				seen[sup] = true
+				classes = append(classes, sup)
+			}
+		}
+	}
+	if !found {
+		report("", "missing objectClass attribute")
+		return errs
+	}
+
+	if msg := s.structuralChain(classes); msg != "" {
+		report("", msg)
+	}
+
+	var allowed []*AttributeType
+	extensible := false
+	for _, c := range classes {
+		if c.OID == extensibleObject {
+			extensible = true
+		}
+		for _, name := range c.Must {
+			at, ok := s.AttributeType(name)
+			if !ok {
+				report("", "object class %s requires an undefined attribute type: %s", c.Name(), name)
+				continue
+			}
+			allowed = append(allowed, at)
+			if !s.hasAttribute(attributes, at) {
+				report(at.Name(), "missing attribute required by object class %s", c.Name())
+			}
+		}
+		for _, name := range c.May {
+			if at, ok := s.AttributeType(name); ok {
+				allowed = append(allowed, at)
+			}
+		}
+	}
+
+	// the values are counted per attribute, the same attribute type can have
+	// multiple names and "cn;lang-en" is another attribute than "cn"
+	type attribute struct {
+		at      *AttributeType
+		options string
+	}
+	values := make(map[attribute]int)
+	for _, a := range attributes {
+		at, ok := s.AttributeType(a.Type)
+		if !ok {
+			if !strings.EqualFold(a.Type, "objectClass") {
+				report(a.Type, "undefined attribute type")
+			}
+			continue
+		}
+		key := attribute{at, strings.Join(a.Description().TaggingOptions(), ";")}
+		// operational attributes are maintained by the server, they are not
+		// allowed by the object classes
+		operational := at.Usage != UserApplications
+		if values[key]++; values[key] == 1 && !extensible && !operational && !s.isAllowed(at, allowed) {
+			report(a.Type, "attribute not allowed by the object classes")
+		}
+		if values[key] == 2 && at.SingleValue {
+			report(a.Type, "multiple values for a single-valued attribute")
+		}
+		// values referenced by an url and values transferred in their binary
+		// form can not be validated
+		if a.URL || a.Description().Binary() {
+			continue
+		}
+		if err := ValidateSyntax(s.syntax(at), a.Value); err != nil {
+			report(a.Type, "%v", err)
+		}
+	}
+	return errs
+}
+
+// structuralChain checks that the structural object classes form exactly one
+// superclass chain, it returns a message if they do not.
+func (s *Schema) structuralChain(classes []*ObjectClass) string {
+	var structural []*ObjectClass
+	for _, c := range classes {
+		if c.Kind == Structural {
+			structural = append(structural, c)
+		}
+	}
+	if len(structural) == 0 {
+		return "no structural object class"
+	}
+	for _, c := range structural {
+		chain := make(map[*ObjectClass]bool)
+		for _, sup := range s.superclasses(c) {
+			chain[sup] = true
+		}
+		all := true
+		for _, other := range structural {
+			all = all && chain[other]
+		}
+		if all {
+			return ""
+		}
+	}
+	names := make([]string, len(structural))
+	for i, c := range structural {
+		names[i] = c.Name()
+	}
+	return fmt.Sprintf("multiple structural object class chains: %s", strings.Join(names, ", "))
+}
+
+// hasAttribute checks whether one of the attributes is of the given type or one
+// of its subtypes.
+func (s *Schema) hasAttribute(attributes []ldif.Attribute, typ *AttributeType) bool {
+	for _, a := range attributes {
+		if at, ok := s.AttributeType(a.Type); ok && s.isSubtype(at, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowed checks whether the attribute type is one of the allowed types or a
+// subtype of them.
+func (s *Schema) isAllowed(typ *AttributeType, allowed []*AttributeType) bool {
+	for _, a := range allowed {
+		if s.isSubtype(typ, a) {
+			return true
+		}
+	}
+	return false
+}
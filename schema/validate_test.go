@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elimity-com/ldif"
+)
+
+func TestValidate(t *testing.T) {
+	s := newTestSchema(t)
+	for _, test := range []struct {
+		name   string
+		ldif   string
+		errors []string
+	}{
+		{
+			name: "valid",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: top
+objectclass: organizationalPerson
+objectclass: uidObject
+cn: Barbara Jensen
+cn;lang-en: Babs Jensen
+sn: Jensen
+uid: bjensen
+title: Sailor
+`,
+		},
+		{
+			name: "add record",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+changetype: add
+objectclass: person
+sn: Jensen
+title: Sailor
+
+dn: cn=Barbara Jensen, dc=airius, dc=com
+changetype: delete
+`,
+			errors: []string{
+				"cn=Barbara Jensen, dc=airius, dc=com: cn: missing attribute required by object class person",
+				"cn=Barbara Jensen, dc=airius, dc=com: title: attribute not allowed by the object classes",
+			},
+		},
+		{
+			name: "undefined",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: person
+objectclass: sailor
+cn: Barbara Jensen
+sn: Jensen
+boat: Jensen
+`,
+			errors: []string{
+				"cn=Barbara Jensen, dc=airius, dc=com: undefined object class: sailor",
+				"cn=Barbara Jensen, dc=airius, dc=com: boat: undefined attribute type",
+			},
+		},
+		{
+			name: "structural",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: uidObject
+uid: bjensen
+
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: person
+objectclass: organizationalUnit
+cn: Barbara Jensen
+sn: Jensen
+ou: Sailors
+`,
+			errors: []string{
+				"cn=Barbara Jensen, dc=airius, dc=com: no structural object class",
+				"cn=Barbara Jensen, dc=airius, dc=com: multiple structural object class chains: person, organizationalUnit",
+			},
+		},
+		{
+			name: "single-valued",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: person
+objectclass: extensibleObject
+cn: Barbara Jensen
+sn: Jensen
+preferredLanguage: en
+preferredLanguage: nl
+preferredLanguage: fr
+`,
+			errors: []string{
+				"cn=Barbara Jensen, dc=airius, dc=com: preferredLanguage: multiple values for a single-valued attribute",
+			},
+		},
+		{
+			name: "single-valued names",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: person
+objectclass: extensibleObject
+cn: Barbara Jensen
+sn: Jensen
+preferredLanguage: en
+preferredLanguage;lang-nl: nl
+2.16.840.1.113730.3.1.39: fr
+`,
+			errors: []string{
+				"cn=Barbara Jensen, dc=airius, dc=com: 2.16.840.1.113730.3.1.39: multiple values for a single-valued attribute",
+			},
+		},
+		{
+			name: "syntax",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+objectclass: person
+cn: Barbara Jensen
+sn: Jensen
+telephonenumber: +1 408 555 1212
+telephonenumber: +1 408 555 1212 #1
+telephonenumber:< file:///tmp/phone
+`,
+			errors: []string{
+				`cn=Barbara Jensen, dc=airius, dc=com: telephonenumber: invalid TelephoneNumber: "+1 408 555 1212 #1"`,
+			},
+		},
+		{
+			name: "missing objectclass",
+			ldif: `version: 1
+dn: cn=Barbara Jensen, dc=airius, dc=com
+cn: Barbara Jensen
+`,
+			errors: []string{
+				"cn=Barbara Jensen, dc=airius, dc=com: missing objectClass attribute",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			records, err := ldif.Parse(test.ldif)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var errors []string
+			for _, err := range s.Validate(records) {
+				errors = append(errors, err.Error())
+			}
+			if !reflect.DeepEqual(errors, test.errors) {
+				t.Errorf("expected %q, got %q", test.errors, errors)
+			}
+		})
+	}
+}
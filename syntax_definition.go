@@ -1,6 +1,10 @@
 package ldif
 
-import . "github.com/elimity-com/abnf/operators"
+import (
+	"unicode/utf8"
+
+	. "github.com/elimity-com/abnf/operators"
+)
 
 func File(s []rune) Alternatives {
 	return Alts(
@@ -13,6 +17,7 @@ func File(s []rune) Alternatives {
 func ldifContent(s []rune) Alternatives {
 	return Concat(
 		`ldif-content`,
+		Repeat0Inf(`*comment-line`, commentLine),
 		versionSpec,
 		Repeat1Inf(`1*(1*SEP ldif-attrval-record)`, Concat(
 			`1*SEP ldif-attrval-record`,
@@ -25,6 +30,7 @@ func ldifContent(s []rune) Alternatives {
 func ldifChanges(s []rune) Alternatives {
 	return Concat(
 		`ldif-changes`,
+		Repeat0Inf(`*comment-line`, commentLine),
 		versionSpec,
 		Repeat1Inf(`1*(1*SEP ldif-change-record)`, Concat(
 			`1*SEP ldif-change-record`,
@@ -34,6 +40,47 @@ func ldifChanges(s []rune) Alternatives {
 	)(s)
 }
 
+// LenientFile is an ldif-file of which the version-spec is optional, as many
+// tools omit it. The first record does not need to be separated from the
+// version-spec by an empty line.
+func LenientFile(s []rune) Alternatives {
+	return Alts(
+		`ldif-file`,
+		lenientContent,
+		lenientChanges,
+	)(s)
+}
+
+func lenientContent(s []rune) Alternatives {
+	return Concat(
+		`ldif-content`,
+		Repeat0Inf(`*comment-line`, commentLine),
+		Optional(`[version-spec SEP]`, Concat(`version-spec SEP`, versionSpec, sep)),
+		Repeat0Inf(`*SEP`, sep),
+		ldifAttrvalRecord,
+		Repeat0Inf(`*(1*SEP ldif-attrval-record)`, Concat(
+			`1*SEP ldif-attrval-record`,
+			Repeat1Inf(`1*SEP`, sep),
+			ldifAttrvalRecord,
+		)),
+	)(s)
+}
+
+func lenientChanges(s []rune) Alternatives {
+	return Concat(
+		`ldif-changes`,
+		Repeat0Inf(`*comment-line`, commentLine),
+		Optional(`[version-spec SEP]`, Concat(`version-spec SEP`, versionSpec, sep)),
+		Repeat0Inf(`*SEP`, sep),
+		ldifChangeRecord,
+		Repeat0Inf(`*(1*SEP ldif-change-record)`, Concat(
+			`1*SEP ldif-change-record`,
+			Repeat1Inf(`1*SEP`, sep),
+			ldifChangeRecord,
+		)),
+	)(s)
+}
+
 func ldifAttrvalRecord(s []rune) Alternatives {
 	return Concat(
 		`ldif-attrval-record`,
@@ -95,7 +142,7 @@ func distinguishedName(s []rune) Alternatives {
 
 // a distinguishedName which has been base64 encoded
 func base64DistinguishedName(s []rune) Alternatives {
-	return base64utf8String(s)
+	return Alts(`base64-distinguishedName`, base64utf8String)(s)
 }
 
 // a relative distinguished name, defined as <name-component> in RFC2253
@@ -105,35 +152,41 @@ func rdn(s []rune) Alternatives {
 
 // an rdn which has been base64 encoded
 func base64Rdn(s []rune) Alternatives {
-	return base64utf8String(s)
+	return Alts(`base64-rdn`, base64utf8String)(s)
 }
 
 func control(s []rune) Alternatives {
-	return Concat(
-		`control`,
-		StringCS(`control:`, "control:"),
-		fill,
-		ldapOid, // control type
-		Repeat(`0*1(1*SPACE ("true" / "false"))`, 0, 1, Concat(
-			`1*SPACE ("true" / "false")`,
-			Repeat1Inf(`1*SPACE`, space),
-			Alts(
-				`"true" / "false"`,
-				StringCS(`true`, "true"),
-				StringCS(`false`, "false"),
-			),
-		)),                                         // criticality
-		Repeat(`0*1(value-spec)`, 0, 1, valueSpec), // control value
-		sep,
-	)(s)
+	return Concat(`control`, controlParts...)(s)
 }
 
+// The rules that make up a single line of a record are concatenations of their
+// parts, which are also matched one by one to find the position of an error
+// within the line, see diagnose.
+var controlParts = []Operator{
+	StringCS(`control:`, "control:"),
+	fill,
+	ldapOid, // control type
+	Repeat(`0*1(1*SPACE ("true" / "false"))`, 0, 1, Concat(
+		`1*SPACE ("true" / "false")`,
+		Repeat1Inf(`1*SPACE`, space),
+		Alts(
+			`"true" / "false"`,
+			StringCS(`true`, "true"),
+			StringCS(`false`, "false"),
+		),
+	)), // criticality
+	Repeat(`0*1(value-spec)`, 0, 1, valueSpec), // control value
+	sep,
+}
+
+// An LDAPOID, as defined in RFC2251. RFC2849 defines it as
+// 1*DIGIT 0*1("." 1*DIGIT), which only allows for a single ".".
 func ldapOid(s []rune) Alternatives {
 	return Concat(
 		`ldap-oid`,
 		Repeat1Inf(`1*DIGIT`, digit),
-		Repeat(`0*1("." 1*DIGIT)`, 0, 1, Concat(
-			`0*1("." 1*DIGIT)`,
+		Repeat0Inf(`*("." 1*DIGIT)`, Concat(
+			`"." 1*DIGIT`,
 			Rune(`.`, '.'),
 			Repeat1Inf(`1*DIGIT`, digit),
 		)),
@@ -141,12 +194,13 @@ func ldapOid(s []rune) Alternatives {
 }
 
 func attrvalSpec(s []rune) Alternatives {
-	return Concat(
-		`attrval-spec`,
-		attributeDescription,
-		valueSpec,
-		sep,
-	)(s)
+	return Concat(`attrval-spec`, attrvalSpecParts...)(s)
+}
+
+var attrvalSpecParts = []Operator{
+	attributeDescription,
+	valueSpec,
+	sep,
 }
 
 func valueSpec(s []rune) Alternatives {
@@ -176,8 +230,68 @@ func valueSpec(s []rune) Alternatives {
 	)(s)
 }
 
+// a Uniform Resource Locator, as defined in RFC1738
 func url(s []rune) Alternatives {
-	return Concat(`url`)(s) // TODO
+	return Concat(
+		`url`,
+		scheme,
+		Rune(`:`, ':'),
+		Repeat0Inf(`*xchar`, xchar),
+	)(s)
+}
+
+// upper case letters are treated as equivalent to lower case
+func scheme(s []rune) Alternatives {
+	return Repeat1Inf(`scheme`, Alts(
+		`ALPHA / DIGIT / "+" / "-" / "."`,
+		alpha,
+		digit,
+		Rune(`+`, '+'),
+		Rune(`-`, '-'),
+		Rune(`.`, '.'),
+	))(s)
+}
+
+func xchar(s []rune) Alternatives {
+	return Alts(
+		`xchar`,
+		unreserved,
+		reserved,
+		escape,
+	)(s)
+}
+
+func unreserved(s []rune) Alternatives {
+	return Alts(
+		`unreserved`,
+		alpha,
+		digit,
+		// safe
+		Rune(`$`, '$'), Rune(`-`, '-'), Rune(`_`, '_'), Rune(`.`, '.'), Rune(`+`, '+'),
+		// extra
+		Rune(`!`, '!'), Rune(`*`, '*'), Rune(`'`, '\''), Rune(`(`, '('), Rune(`)`, ')'), Rune(`,`, ','),
+	)(s)
+}
+
+func reserved(s []rune) Alternatives {
+	return Alts(
+		`reserved`,
+		Rune(`;`, ';'), Rune(`/`, '/'), Rune(`?`, '?'), Rune(`:`, ':'),
+		Rune(`@`, '@'), Rune(`&`, '&'), Rune(`=`, '='),
+	)(s)
+}
+
+func escape(s []rune) Alternatives {
+	return Concat(
+		`escape`,
+		Rune(`%`, '%'),
+		RepeatN(`2HEXDIG`, 2, Alts(
+			`HEXDIG`,
+			digit,
+			Range(`A-F`, 'A', 'F'),
+			Range(`a-f`, 'a', 'f'),
+		)),
+	)(s)
 }
 
 func attributeDescription(s []rune) Alternatives {
@@ -217,8 +331,26 @@ func options(s []rune) Alternatives {
 	)(s)
 }
 
+// Active Directory returns the values of large multi-valued attributes in
+// chunks, of which the attribute descriptions have a "range=<low>-<high>"
+// option (e.g. "member;range=0-1499"). RFC 2849 does not allow for the "=" in
+// the option, but these chunks end up in exported LDIF files.
 func option(s []rune) Alternatives {
-	return Repeat1Inf(`option`, optChar)(s)
+	return Alts(
+		`option`,
+		Repeat1Inf(`1*opt-char`, optChar),
+		Concat(
+			`range-option`,
+			String(`range=`, "range="),
+			Repeat1Inf(`1*DIGIT`, digit),
+			Rune(`-`, '-'),
+			Alts(
+				`1*DIGIT / "*"`,
+				Repeat1Inf(`1*DIGIT`, digit),
+				Rune(`*`, '*'),
+			),
+		),
+	)(s)
 }
 
 func attrTypeChars(s []rune) Alternatives {
@@ -237,121 +369,166 @@ func optChar(s []rune) Alternatives {
 func changerecord(s []rune) Alternatives {
 	return Concat(
 		`changerecord`,
-		StringCS(`changetype:`, "changetype:"),
-		fill,
-		Alts(
+		append(changetypeParts, Alts(
 			`change-add / change-delete / change-modify / change-moddn`,
 			changeAdd,
 			changeDelete,
 			changeModify,
 			changeModdn,
-		),
+		))...,
 	)(s)
 }
 
+// the "changetype:" line, up to the change type that starts the rule of
+// the change
+var changetypeParts = []Operator{
+	StringCS(`changetype:`, "changetype:"),
+	fill,
+}
+
 func changeAdd(s []rune) Alternatives {
-	return Concat(
-		`change-add`,
-		StringCS(`add`, "add"),
-		sep,
-		Repeat1Inf(`1*attrval-spec`, attrvalSpec),
-	)(s)
+	return Concat(`change-add`, changeAddParts...)(s)
+}
+
+var changeAddParts = []Operator{
+	StringCS(`add`, "add"),
+	sep,
+	Repeat1Inf(`1*attrval-spec`, attrvalSpec),
 }
 
 func changeDelete(s []rune) Alternatives {
-	return Concat(
-		`change-delete`,
-		StringCS(`delete`, "delete"),
-		sep,
-	)(s)
+	return Concat(`change-delete`, changeDeleteParts...)(s)
+}
+
+var changeDeleteParts = []Operator{
+	StringCS(`delete`, "delete"),
+	sep,
 }
 
 func changeModdn(s []rune) Alternatives {
-	return Concat(
-		`change-moddn`,
-		Alts(
-			`"modrdn" / "moddn"`,
-			StringCS(`modrdn`, "modrdn"),
-			StringCS(`moddn`, "moddn"),
+	return Concat(`change-moddn`, changeModdnParts...)(s)
+}
+
+var changeModdnParts = []Operator{
+	Alts(
+		`"modrdn" / "moddn"`,
+		StringCS(`modrdn`, "modrdn"),
+		StringCS(`moddn`, "moddn"),
+	),
+	sep,
+	Concat(`"newrdn:" (FILL rdn / ":" FILL base64-rdn) SEP`, newRDNParts...),
+	Concat(`"deleteoldrdn:" FILL ("0" / "1") SEP`, deleteOldRDNParts...),
+	Repeat(`0*1("newsuperior:" (FILL distinguishedName / ":" FILL base64-distinguishedName) SEP)`, 0, 1, Concat(
+		`"newsuperior:" (FILL distinguishedName / ":" FILL base64-distinguishedName) SEP`,
+		newSuperiorParts...,
+	)),
+}
+
+var newRDNParts = []Operator{
+	StringCS(`newrdn:`, "newrdn:"),
+	Alts(
+		`FILL rdn / ":" FILL base64-rdn`,
+		Concat(
+			`FILL rdn`,
+			fill,
+			rdn,
 		),
-		sep,
-		StringCS(`newrdn:`, "newrdn:"),
-		Alts(
-			`FILL rdn / ":" FILL base64-rdn`,
-			Concat(
-				`FILL rdn`,
-				fill,
-				rdn,
-			),
-			Concat(
-				`":" FILL base64-rdn`,
-				Rune(`:`, ':'),
-				fill,
-				base64Rdn,
-			),
+		Concat(
+			`":" FILL base64-rdn`,
+			Rune(`:`, ':'),
+			fill,
+			base64Rdn,
 		),
-		sep,
-		StringCS(`deleteoldrdn:`, "deleteoldrdn:"),
-		fill,
-		Alts(
-			`"0" / "1"`,
-			Rune(`0`, '0'),
-			Rune(`1`, '1'),
+	),
+	sep,
+}
+
+var deleteOldRDNParts = []Operator{
+	StringCS(`deleteoldrdn:`, "deleteoldrdn:"),
+	fill,
+	Alts(
+		`"0" / "1"`,
+		Rune(`0`, '0'),
+		Rune(`1`, '1'),
+	),
+	sep,
+}
+
+var newSuperiorParts = []Operator{
+	StringCS(`newsuperior:`, "newsuperior:"),
+	Alts(
+		`FILL distinguishedName / ":" FILL base64-distinguishedName`,
+		Concat(
+			`FILL distinguishedName`,
+			fill,
+			distinguishedName,
 		),
-		sep,
-		Repeat(`0*1("newsuperior:" (FILL distinguishedName / ":" FILL base64-distinguishedName) SEP)`, 0, 1, Concat(
-			`"newsuperior:" (FILL distinguishedName / ":" FILL base64-distinguishedName) SEP`,
-			StringCS(`newsuperior:`, "newsuperior:"),
-			Alts(
-				`FILL distinguishedName / ":" FILL base64-distinguishedName`,
-				Concat(
-					`FILL distinguishedName`,
-					fill,
-					distinguishedName,
-				),
-				Concat(
-					`":" FILL base64-distinguishedName`,
-					Rune(`:`, ':'),
-					fill,
-					base64DistinguishedName,
-				),
-			),
-			sep,
-		)),
-	)(s)
+		Concat(
+			`":" FILL base64-distinguishedName`,
+			Rune(`:`, ':'),
+			fill,
+			base64DistinguishedName,
+		),
+	),
+	sep,
 }
 
 func changeModify(s []rune) Alternatives {
-	return Concat(
-		`change-modify`,
-		StringCS(`modify`, "modify"),
-		sep,
-		Repeat0Inf(`*mod-spec`, modSpec),
-	)(s)
+	return Concat(`change-modify`, changeModifyParts...)(s)
+}
+
+var changeModifyParts = []Operator{
+	StringCS(`modify`, "modify"),
+	sep,
+	Repeat0Inf(`*mod-spec`, modSpec),
 }
 
 func modSpec(s []rune) Alternatives {
 	return Concat(
 		`mod-spec`,
-		Alts(
-			`"add:" / "delete:" / "replace:"`,
-			StringCS(`add:`, "add:"),
-			StringCS(`delete:`, "delete:"),
-			StringCS(`replace:`, "replace:"),
-		),
-		fill,
-		attributeDescription,
-		sep,
-		Repeat0Inf(`*attrval-spec`, attrvalSpec),
-		Rune(`-`, '-'),
-		sep,
+		append(modSpecParts,
+			Repeat0Inf(`*attrval-spec`, attrvalSpec),
+			Rune(`-`, '-'),
+			sep,
+		)...,
+	)(s)
+}
+
+// the first line of a mod-spec, the mod-spec continues with the values
+var modSpecParts = []Operator{
+	Alts(
+		`"add:" / "delete:" / "replace:"`,
+		StringCS(`add:`, "add:"),
+		StringCS(`delete:`, "delete:"),
+		StringCS(`replace:`, "replace:"),
+	),
+	fill,
+	attributeDescription,
+	sep,
+}
+
+// Lines beginning with a "#" are comments, they are not part of the formal
+// syntax of RFC2849. A comment line is always preceded by a separator, or is
+// at the start of the file.
+func commentLine(s []rune) Alternatives {
+	return Concat(
+		`comment-line`,
+		Rune(`#`, '#'),
+		Repeat0Inf(`*comment-char`, commentChar),
+		lineSep,
 	)(s)
 }
 
 var (
 	space = Rune(`SPACE`, '\x20') // ASCII SP, space
 	fill  = Repeat0Inf(`FILL`, space)
-	sep   = Alts(`SEP`, Concat(`CR LF`, cr, lf), lf)
+	// a separator, followed by the comment lines that precede the next line
+	sep = Concat(
+		`SEP`,
+		lineSep,
+		Repeat0Inf(`*comment-line`, commentLine),
+	)
+	lineSep = Alts(`CR LF / LF`, Concat(`CR LF`, cr, lf), lf)
 	cr    = Rune(`CR`, '\x0D') // ASCII CR, carriage return
 	lf    = Rune(`LF`, '\x0A') // ASCII LF, line feed
 	alpha = Alts(
@@ -410,4 +587,11 @@ var (
 		Range(`%x61-7A`, '\x61', '\x7A'), // a-z
 	)
 	base64String = Optional(`BASE64-STRING`, Repeat0Inf(`*(BASE64-CHAR)`, base64Char))
+	// any character except NUL, LF and CR
+	commentChar = Alts(
+		`comment-char`,
+		Range(`%x01-09`, '\x01', '\x09'),
+		Range(`%x0B-0C`, '\x0B', '\x0C'),
+		Range(`%x0E-`, '\x0E', utf8.MaxRune),
+	)
 )
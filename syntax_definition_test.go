@@ -31,10 +31,22 @@ func TestExample(t *testing.T) {
 	})
 }
 
+func TestComments(t *testing.T) {
+	raw, _ := ioutil.ReadFile("testdata/comments.ldif")
+	input := []rune(unfold(string(raw)))
+	f := File(input).Best()
+	if len(f.Value) != len(input) {
+		t.Fatalf("could not parse the complete file, parsed %d of %d", len(f.Value), len(input))
+	}
+	if l := len(f.GetSubNodes("comment-line")); l != 6 {
+		t.Errorf("did not find 6 comments, got %d", l)
+	}
+}
+
 func TestExamples(t *testing.T) {
 	for i := 1; i < 8; i++ {
 		t.Run(fmt.Sprintf("example%d", i), func(t *testing.T) {
-			raw, _ := ioutil.ReadFile("testdata/example1.ldif")
+			raw, _ := ioutil.ReadFile(fmt.Sprintf("testdata/example%d.ldif", i))
 			if File([]rune(string(raw))) == nil {
 				t.Errorf("could not parse ldif file: example%d.ldif", i)
 			}
@@ -0,0 +1,199 @@
+package ldif
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Writer writes records as an LDIF file. It starts the file with a
+// version-spec and separates all records by an empty line. The output of the
+// Writer is buffered, Flush needs to be called after the last record.
+type Writer struct {
+	// Width is the maximum length of a line, longer lines are folded onto
+	// continuation lines. Lines are never folded if Width is less than two.
+	Width int
+
+	w *bufio.Writer
+	// records is the number of records that have been written.
+	records int
+	// changes indicates whether the records are change records.
+	changes bool
+}
+
+// NewWriter returns a new Writer that writes to w. Lines are folded at 76
+// characters by default.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		Width: 76,
+		w:     bufio.NewWriter(w),
+	}
+}
+
+// Write writes a single record. All records of a file have to be either of
+// type *Entry or change records.
+func (w *Writer) Write(r Record) error {
+	_, entry := r.(*Entry)
+	if w.records != 0 && w.changes == entry {
+		return fmt.Errorf("ldif file can not contain both entries and change records")
+	}
+
+	var lines []string
+	switch r := r.(type) {
+	case *Entry:
+		lines = commentSpecs(r.Comments)
+		lines = append(lines, "dn"+encodeValue(r.DN))
+		lines = append(lines, attrvalSpecs(r.Attributes)...)
+	case ChangeRecord:
+		lines = changeRecordSpecs(r)
+	default:
+		return fmt.Errorf("unknown record type: %T", r)
+	}
+
+	if w.records == 0 {
+		lines = append([]string{"version: 1"}, lines...)
+	} else {
+		lines = append([]string{""}, lines...)
+	}
+	for _, line := range lines {
+		if _, err := w.w.WriteString(w.fold(line)); err != nil {
+			return err
+		}
+	}
+	w.changes = !entry
+	w.records++
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// fold folds the given line, if needed, and terminates it with a separator.
+func (w *Writer) fold(line string) string {
+	if w.Width < 2 || len(line) <= w.Width {
+		return line + "\n"
+	}
+
+	var b strings.Builder
+	for n := w.Width; line != ""; n = w.Width - 1 {
+		if len(line) <= n {
+			n = len(line)
+		}
+		// comments are not encoded, so they can contain multi-byte characters
+		for 0 < n && n < len(line) && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		if n == 0 {
+			_, n = utf8.DecodeRuneInString(line)
+		}
+		if b.Len() != 0 {
+			b.WriteString("\n ")
+		}
+		b.WriteString(line[:n])
+		line = line[n:]
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func changeRecordSpecs(r ChangeRecord) []string {
+	header := r.Header()
+	lines := append(commentSpecs(header.Comments), "dn"+encodeValue(header.DN))
+	for _, c := range header.Controls {
+		control := "control: " + c.OID
+		if c.Criticality {
+			control += " true"
+		}
+		if c.URL {
+			control += ":< " + c.Value
+		} else if c.Value != "" {
+			control += encodeValue(c.Value)
+		}
+		lines = append(lines, control)
+	}
+
+	switch r := r.(type) {
+	case *AddRecord:
+		lines = append(lines, "changetype: add")
+		lines = append(lines, attrvalSpecs(r.Attributes)...)
+	case *DeleteRecord:
+		lines = append(lines, "changetype: delete")
+	case *ModifyRecord:
+		lines = append(lines, "changetype: modify")
+		for _, m := range r.Modifications {
+			lines = append(lines, fmt.Sprintf("%s: %s", m.Type, m.Attribute))
+			lines = append(lines, attrvalSpecs(m.Values)...)
+			lines = append(lines, "-")
+		}
+	case *ModDNRecord:
+		lines = append(lines, "changetype: modrdn")
+		lines = append(lines, "newrdn"+encodeValue(r.NewRDN))
+		if r.DeleteOldRDN {
+			lines = append(lines, "deleteoldrdn: 1")
+		} else {
+			lines = append(lines, "deleteoldrdn: 0")
+		}
+		if r.NewSuperior != "" {
+			lines = append(lines, "newsuperior"+encodeValue(r.NewSuperior))
+		}
+	}
+	return lines
+}
+
+// commentSpecs returns the comment lines of the comments. A comment that spans
+// multiple lines is written as multiple comment lines, otherwise the lines after
+// the first one would not be part of the comment.
+func commentSpecs(comments []string) []string {
+	lines := make([]string, 0, len(comments))
+	for _, c := range comments {
+		c = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(c)
+		for _, l := range strings.Split(c, "\n") {
+			lines = append(lines, "#"+l)
+		}
+	}
+	return lines
+}
+
+func attrvalSpecs(attributes []Attribute) []string {
+	lines := make([]string, 0, len(attributes))
+	for _, a := range attributes {
+		if a.URL {
+			lines = append(lines, fmt.Sprintf("%s:< %s", a.Type, a.Value))
+			continue
+		}
+		lines = append(lines, a.Type+encodeValue(a.Value))
+	}
+	return lines
+}
+
+// encodeValue returns the value-spec for the given value, it is base64 encoded if
+// it is not a SAFE-STRING.
+func encodeValue(value string) string {
+	if value == "" {
+		return ":"
+	}
+	if isSafeString(value) {
+		return ": " + value
+	}
+	return ":: " + base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// isSafeString checks whether the given value is a SAFE-STRING. Values that end
+// with a space are not considered safe either, as recommended by RFC 2849.
+func isSafeString(value string) bool {
+	for i, r := range value {
+		rule := safeChar
+		if i == 0 {
+			rule = safeInitChar
+		}
+		if rule([]rune{r}) == nil {
+			return false
+		}
+	}
+	return !strings.HasSuffix(value, " ")
+}
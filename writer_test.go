@@ -0,0 +1,158 @@
+package ldif
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Width = 20
+	for _, record := range []Record{
+		&AddRecord{
+			Change: Change{
+				DN:       "cn=Gern Jensen,dc=airius,dc=com",
+				Controls: []Control{{OID: "1.2.840.113556.1.4.805", Criticality: true}},
+			},
+			Attributes: []Attribute{
+				{Type: "cn", Value: "Gern Jensen"},
+				{Type: "description", Value: "control\rcharacter"},
+				{Type: "jpegphoto", Value: "file:///photo.jpg", URL: true},
+			},
+		},
+		&ModDNRecord{
+			Change: Change{
+				DN:       "ou=営業部,o=Airius",
+				Comments: []string{" dn: ou=<営業部>,o=Airius"},
+			},
+			NewRDN:       "ou=Sales",
+			DeleteOldRDN: true,
+		},
+		&ModifyRecord{
+			Change: Change{DN: ""},
+			Modifications: []Modification{
+				{Type: ModReplace, Attribute: "sn", Values: []Attribute{{Type: "sn", Value: " leading space"}}},
+				{Type: ModDelete, Attribute: "description"},
+			},
+		},
+	} {
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `version: 1
+dn: cn=Gern Jensen,d
+ c=airius,dc=com
+control: 1.2.840.113
+ 556.1.4.805 true
+changetype: add
+cn: Gern Jensen
+description:: Y29udH
+ JvbA1jaGFyYWN0ZXI=
+jpegphoto:< file:///
+ photo.jpg
+
+# dn: ou=<営業部>
+ ,o=Airius
+dn:: b3U95Za25qWt6YO
+ oLG89QWlyaXVz
+changetype: modrdn
+newrdn: ou=Sales
+deleteoldrdn: 1
+
+dn:
+changetype: modify
+replace: sn
+sn:: IGxlYWRpbmcgc3B
+ hY2U=
+-
+delete: description
+-
+`; b.String() != expected {
+		t.Errorf("unexpected output:\n%s", b.String())
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	for _, file := range []string{
+		"testdata/example1.ldif",
+		"testdata/example2.ldif",
+		"testdata/example3.ldif",
+		"testdata/example4.ldif",
+		"testdata/example5.ldif",
+		"testdata/example6.ldif",
+		"testdata/example7.ldif",
+		"testdata/folded.ldif",
+	} {
+		raw, _ := ioutil.ReadFile(file)
+		records, err := Parse(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b strings.Builder
+		w := NewWriter(&b)
+		for _, record := range records {
+			if err := w.Write(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		written, err := Parse(b.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(records, written) {
+			t.Errorf("records of %s changed after writing:\n%s", file, b.String())
+		}
+	}
+}
+
+func TestWriterMixed(t *testing.T) {
+	w := NewWriter(ioutil.Discard)
+	if err := w.Write(&Entry{DN: "cn=a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(&DeleteRecord{Change: Change{DN: "cn=b"}}); err == nil {
+		t.Error("no error for mixed records")
+	}
+}
+
+func TestWriterComments(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	entry := &Entry{
+		DN:         "cn=a",
+		Attributes: []Attribute{{Type: "cn", Value: "a"}},
+		Comments:   []string{" first\n\ndn: cn=b\r\ncn: b"},
+	}
+	if err := w.Write(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "version: 1\n# first\n#\n#dn: cn=b\n#cn: b\ndn: cn=a\ncn: a\n"; b.String() != expected {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+
+	// the lines of the comment do not become records or attributes
+	records, err := Parse(b.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{" first", "", "dn: cn=b", "cn: b"}; len(records) != 1 || !reflect.DeepEqual(records[0].(*Entry).Comments, expected) {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
@@ -0,0 +1,25 @@
+package ldif
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML returns the YAML representation of the records, a sequence of
+// record mappings. It uses the same schema as MarshalJSON.
+func MarshalYAML(records []Record) ([]byte, error) {
+	rs, err := newJSONRecords(records)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(rs)
+}
+
+// UnmarshalYAML parses the YAML representation of records, as returned by
+// MarshalYAML.
+func UnmarshalYAML(data []byte) ([]Record, error) {
+	var rs []jsonRecord
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, err
+	}
+	return jsonRecords(rs)
+}
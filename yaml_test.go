@@ -0,0 +1,64 @@
+package ldif
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestYAML(t *testing.T) {
+	data, err := MarshalYAML(mappingChanges[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `- dn: ou=Product Development, dc=airius, dc=com
+  changetype: delete
+  controls:
+    - oid: 1.2.840.113556.1.4.805
+      criticality: true
+      base64: MAA=
+    - oid: 1.2.3
+      value: text
+    - oid: 1.2.4
+      url: file:///control
+`; string(data) != expected {
+		t.Errorf("unexpected yaml:\n%s", data)
+	}
+
+	for _, records := range [][]Record{mappingRecords, mappingChanges} {
+		data, err := MarshalYAML(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := UnmarshalYAML(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed, records) {
+			t.Errorf("records changed after a round trip:\n%s", data)
+		}
+	}
+}
+
+func TestYAMLExamples(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.ldif")
+	for _, file := range files {
+		raw, _ := ioutil.ReadFile(file)
+		records, err := Parse(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := MarshalYAML(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := UnmarshalYAML(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed, records) {
+			t.Errorf("%s: records changed after a round trip:\n%s", file, data)
+		}
+	}
+}